@@ -1,26 +1,59 @@
 package main
 
 import (
+	"context"
 	"dailybot/internal/admin"
+	"dailybot/internal/api"
 	"dailybot/internal/bot"
 	"dailybot/internal/config"
+	"dailybot/internal/metrics"
+	"dailybot/internal/ratelimit"
+	"dailybot/internal/storage"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
+// Сколько ждем завершения обработчиков при остановке.
+// Должно быть меньше stop_grace_period в docker-compose.yml
+const shutdownTimeout = 25 * time.Second
+
+// Сколько раз пробуем подключиться к явно заданной базе: она может
+// подниматься одновременно с ботом. Паузы между попытками растут: 1, 2, 4, 8 с
+const dbConnectAttempts = 5
+
 func main() {
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatal("Failed to load config:", err)
 	}
 
+	// Подключаемся к базе. Без нее бот работает, но подписки, оповещения,
+	// профили и статистика живут только до рестарта
+	store, err := connectStorage(cfg)
+	if err != nil {
+		log.Printf("Database unavailable, running without persistence: %v", err)
+		log.Println("Subscriptions, alerts, profiles and stats will be lost on restart")
+	}
+
+	// Лимиты запросов общие для бота, внешних API и админки
+	limiter := ratelimit.New(ratelimit.DefaultCommandLimits, ratelimit.DefaultProviderLimits)
+	api.SetBudget(limiter)
+
 	// Создаем простую админку
-	adminServer := admin.NewSimpleAdmin(cfg)
+	adminServer := admin.NewSimpleAdmin(cfg, store, limiter)
 
 	// Создаем бота
-	b, err := bot.New(cfg, adminServer)
+	b, err := bot.New(cfg, adminServer, store, limiter)
 	if err != nil {
 		log.Fatal("Failed to create bot:", err)
 	}
+	adminServer.AttachBot(b)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	// Запускаем админку в отдельной горутине
 	go func() {
@@ -28,7 +61,78 @@ func main() {
 		adminServer.Start()
 	}()
 
-	// Запускаем бота (блокирующий вызов)
+	// Метрики - на своем адресе, отдельно от админки
+	var metricsServer *metrics.Server
+	if cfg.MetricsListen != "" {
+		metricsServer = metrics.NewServer(cfg.MetricsListen, cfg.MetricsToken)
+		go metricsServer.Start()
+	}
+
+	// Запускаем бота
 	log.Println("Starting Telegram bot...")
-	b.Start()
+	botErr := make(chan error, 1)
+	go func() {
+		botErr <- b.Start()
+	}()
+
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+		log.Println("Shutdown signal received, stopping...")
+	case err := <-botErr:
+		if err != nil {
+			log.Printf("Failed to start bot: %v", err)
+			exitCode = 1
+		}
+	}
+
+	// Повторный сигнал завершит процесс сразу
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// Сначала перестаем принимать обновления и дожидаемся обработчиков,
+	// затем останавливаем админку, метрики и записываем статистику, в конце закрываем базу
+	b.Stop(shutdownCtx)
+
+	if err := adminServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Admin panel shutdown: %v", err)
+	}
+
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Metrics server shutdown: %v", err)
+		}
+	}
+
+	if store != nil {
+		if err := store.Close(); err != nil {
+			log.Printf("Failed to close database: %v", err)
+		}
+	}
+
+	log.Println("Stopped")
+	os.Exit(exitCode)
+}
+
+// connectStorage подключается к базе. Базу по умолчанию пробуем один раз,
+// явно заданную - несколько раз с растущей паузой
+func connectStorage(cfg *config.Config) (*storage.Storage, error) {
+	attempts := 1
+	if cfg.DatabaseConfigured {
+		attempts = dbConnectAttempts
+	}
+
+	delay := time.Second
+	for attempt := 1; ; attempt++ {
+		store, err := storage.New(cfg.DatabaseURL)
+		if err == nil || attempt == attempts {
+			return store, err
+		}
+
+		log.Printf("Database unavailable (attempt %d/%d), retrying in %s: %v", attempt, attempts, delay, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
 }
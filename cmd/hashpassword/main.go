@@ -0,0 +1,37 @@
+// hashpassword печатает bcrypt-хеш пароля для ADMIN_PASSWORD_HASH.
+// Пароль читается из stdin, чтобы не оставлять его в истории shell:
+//
+//	go run ./cmd/hashpassword
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const minPasswordLength = 8
+
+func main() {
+	fmt.Fprint(os.Stderr, "Admin password: ")
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		log.Fatal("Failed to read password: ", err)
+	}
+	password := strings.TrimRight(line, "\r\n")
+
+	if len(password) < minPasswordLength {
+		log.Fatalf("Password must be at least %d characters", minPasswordLength)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Fatal("Failed to hash password: ", err)
+	}
+	fmt.Println(string(hash))
+}
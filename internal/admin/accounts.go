@@ -0,0 +1,357 @@
+package admin
+
+import (
+	"context"
+	"dailybot/internal/storage"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Role - права учетной записи. Каждая следующая роль включает предыдущие
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"   // только панель со статистикой
+	RoleOperator Role = "operator" // рассылки и блокировки
+	RoleOwner    Role = "owner"    // лимиты, учетные записи и токены API
+)
+
+var roleLevels = map[Role]int{RoleViewer: 1, RoleOperator: 2, RoleOwner: 3}
+
+var roleNames = map[Role]string{
+	RoleViewer:   "Наблюдатель",
+	RoleOperator: "Оператор",
+	RoleOwner:    "Владелец",
+}
+
+// allows - есть ли у роли права required
+func (r Role) allows(required Role) bool {
+	return roleLevels[r] >= roleLevels[required]
+}
+
+func parseRole(value string) (Role, bool) {
+	role := Role(value)
+	_, ok := roleLevels[role]
+	return role, ok
+}
+
+const minPasswordLength = 8
+
+var loginRe = regexp.MustCompile(`^[a-z0-9_.-]{3,32}$`)
+
+// Account - учетная запись админки
+type Account struct {
+	Login        string
+	PasswordHash string
+	Role         Role
+	CreatedAt    time.Time
+	Builtin      bool // владелец из конфига: в панели не меняется и не удаляется
+}
+
+// accountStore - учетные записи в памяти. Созданные в панели сохраняются
+// в базу, владелец из конфига - нет
+type accountStore struct {
+	mu       sync.RWMutex
+	accounts map[string]Account
+}
+
+func newAccountStore(owner Account) *accountStore {
+	return &accountStore{accounts: map[string]Account{owner.Login: owner}}
+}
+
+func (s *accountStore) get(login string) (Account, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	account, ok := s.accounts[login]
+	return account, ok
+}
+
+func (s *accountStore) list() []Account {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	accounts := make([]Account, 0, len(s.accounts))
+	for _, account := range s.accounts {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].Login < accounts[j].Login })
+	return accounts
+}
+
+// put добавляет или заменяет запись. Владельца из конфига заменить нельзя
+func (s *accountStore) put(account Account) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current, ok := s.accounts[account.Login]; ok && current.Builtin {
+		return false
+	}
+	s.accounts[account.Login] = account
+	return true
+}
+
+func (s *accountStore) delete(login string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, ok := s.accounts[login]
+	if !ok || account.Builtin {
+		return false
+	}
+	delete(s.accounts, login)
+	return true
+}
+
+// restoreAccounts загружает учетные записи, созданные в панели
+func (a *SimpleAdmin) restoreAccounts() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	saved, err := a.store.ListAdminAccounts(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to restore admin accounts: %v", err)
+		return
+	}
+
+	for _, s := range saved {
+		role, ok := parseRole(s.Role)
+		if !ok {
+			log.Printf("⚠️ Admin account %s has unknown role %q, skipped", s.Login, s.Role)
+			continue
+		}
+		if !a.accounts.put(Account{Login: s.Login, PasswordHash: s.PasswordHash, Role: role, CreatedAt: s.CreatedAt}) {
+			log.Printf("⚠️ Admin account %s conflicts with ADMIN_LOGIN, skipped", s.Login)
+		}
+	}
+}
+
+// handleAccounts - страница учетных записей, только для владельцев
+func (a *SimpleAdmin) handleAccounts(w http.ResponseWriter, r *http.Request) {
+	sess, account, ok := a.authorize(w, r, RoleOwner)
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		if !checkCSRF(r, sess) {
+			http.Error(w, "Invalid CSRF token", http.StatusForbidden)
+			return
+		}
+
+		target := "/accounts"
+		if err := a.applyAccountsForm(r, account); err != nil {
+			target += "?error=" + url.QueryEscape(err.Error())
+		}
+		http.Redirect(w, r, target, http.StatusSeeOther)
+		return
+	}
+
+	a.showAccounts(w, sess, account, r.URL.Query().Get("error"))
+}
+
+func (a *SimpleAdmin) applyAccountsForm(r *http.Request, actor Account) error {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	login := strings.ToLower(strings.TrimSpace(r.FormValue("login")))
+
+	switch r.FormValue("action") {
+	case "create":
+		if !loginRe.MatchString(login) {
+			return fmt.Errorf("логин: 3-32 символа, латинские буквы, цифры, _ . -")
+		}
+		if _, exists := a.accounts.get(login); exists {
+			return fmt.Errorf("учетная запись %s уже есть", login)
+		}
+		role, ok := parseRole(r.FormValue("role"))
+		if !ok {
+			return fmt.Errorf("неизвестная роль")
+		}
+		hash, err := hashPassword(r.FormValue("password"))
+		if err != nil {
+			return err
+		}
+
+		account := Account{Login: login, PasswordHash: hash, Role: role, CreatedAt: time.Now()}
+		a.accounts.put(account)
+		a.audit(actor.Login, auditAccountCreate, fmt.Sprintf("%s, роль %s", login, role))
+		return a.saveAccount(ctx, account)
+
+	case "role":
+		account, err := a.editableAccount(login, actor)
+		if err != nil {
+			return err
+		}
+		role, ok := parseRole(r.FormValue("role"))
+		if !ok {
+			return fmt.Errorf("неизвестная роль")
+		}
+		if role == account.Role {
+			return nil
+		}
+
+		previous := account.Role
+		account.Role = role
+		a.accounts.put(account)
+		a.audit(actor.Login, auditAccountRole, fmt.Sprintf("%s: %s → %s", login, previous, role))
+		return a.saveAccount(ctx, account)
+
+	case "password":
+		account, ok := a.accounts.get(login)
+		if !ok || account.Builtin {
+			return fmt.Errorf("пароль этой учетной записи задается в конфиге")
+		}
+		hash, err := hashPassword(r.FormValue("password"))
+		if err != nil {
+			return err
+		}
+
+		account.PasswordHash = hash
+		a.accounts.put(account)
+		// Старые сессии с прежним паролем больше не действуют
+		a.sessions.deleteLogin(login)
+		a.audit(actor.Login, auditAccountPassword, login)
+		return a.saveAccount(ctx, account)
+
+	case "delete":
+		if _, err := a.editableAccount(login, actor); err != nil {
+			return err
+		}
+		a.accounts.delete(login)
+		a.sessions.deleteLogin(login)
+		a.audit(actor.Login, auditAccountDelete, login)
+
+		if a.store != nil {
+			if err := a.store.DeleteAdminAccount(ctx, login); err != nil {
+				log.Printf("❌ Failed to delete admin account: %v", err)
+				return fmt.Errorf("учетная запись удалена до перезапуска: не удалось удалить из базы")
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("неизвестное действие")
+}
+
+// editableAccount - учетная запись, роль которой можно менять. Свою роль
+// менять нельзя, чтобы владелец случайно не остался без доступа
+func (a *SimpleAdmin) editableAccount(login string, actor Account) (Account, error) {
+	account, ok := a.accounts.get(login)
+	if !ok {
+		return Account{}, fmt.Errorf("учетная запись не найдена")
+	}
+	if account.Builtin {
+		return Account{}, fmt.Errorf("владелец из конфига меняется только в конфиге")
+	}
+	if account.Login == actor.Login {
+		return Account{}, fmt.Errorf("нельзя изменить или удалить свою учетную запись")
+	}
+	return account, nil
+}
+
+func (a *SimpleAdmin) saveAccount(ctx context.Context, account Account) error {
+	if a.store == nil {
+		return nil
+	}
+
+	err := a.store.SaveAdminAccount(ctx, storage.AdminAccount{
+		Login:        account.Login,
+		PasswordHash: account.PasswordHash,
+		Role:         string(account.Role),
+		CreatedAt:    account.CreatedAt,
+	})
+	if err != nil {
+		log.Printf("❌ Failed to save admin account: %v", err)
+		return fmt.Errorf("изменение действует до перезапуска: не удалось сохранить в базу")
+	}
+	return nil
+}
+
+func hashPassword(password string) (string, error) {
+	if len(password) < minPasswordLength {
+		return "", fmt.Errorf("пароль должен быть не короче %d символов", minPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("не удалось сохранить пароль")
+	}
+	return string(hash), nil
+}
+
+func (a *SimpleAdmin) showAccounts(w http.ResponseWriter, sess session, actor Account, errorText string) {
+	rows := ""
+	for _, account := range a.accounts.list() {
+		actions := `<span class="hint">задается в конфиге</span>`
+		if !account.Builtin {
+			actions = fmt.Sprintf(`<form method="POST">
+                    <input type="hidden" name="csrf" value="%s">
+                    <input type="hidden" name="action" value="password">
+                    <input type="hidden" name="login" value="%s">
+                    <input type="password" name="password" placeholder="Новый пароль" required>
+                    <button type="submit">Сменить пароль</button>
+                </form>`, sess.csrf, html.EscapeString(account.Login))
+		}
+		if !account.Builtin && account.Login != actor.Login {
+			actions += fmt.Sprintf(`<form method="POST">
+                    <input type="hidden" name="csrf" value="%s">
+                    <input type="hidden" name="action" value="role">
+                    <input type="hidden" name="login" value="%s">
+                    %s
+                    <button type="submit">Сменить роль</button>
+                </form>
+                <form method="POST" onsubmit="return confirm('Удалить учетную запись?')">
+                    <input type="hidden" name="csrf" value="%s">
+                    <input type="hidden" name="action" value="delete">
+                    <input type="hidden" name="login" value="%s">
+                    <button type="submit" class="danger">Удалить</button>
+                </form>`,
+				sess.csrf, html.EscapeString(account.Login), roleSelect(account.Role),
+				sess.csrf, html.EscapeString(account.Login))
+		}
+
+		rows += fmt.Sprintf(`<tr><td>%s</td><td>%s</td><td>%s</td><td class="actions">%s</td></tr>`,
+			html.EscapeString(account.Login), roleNames[account.Role], account.CreatedAt.Format("02.01.2006 15:04"), actions)
+	}
+
+	body := fmt.Sprintf(`<div class="info-card">
+            <h3>Учетные записи</h3>
+            <table class="data-table">
+                <tr><th>Логин</th><th>Роль</th><th>Создана</th><th></th></tr>
+                %s
+            </table>
+            <p class="hint">Наблюдатель видит только панель, оператор делает рассылки и блокировки,
+                владелец меняет лимиты и учетные записи.</p>
+        </div>
+
+        <div class="info-card">
+            <h3>Новая учетная запись</h3>
+            <form method="POST">
+                <input type="hidden" name="csrf" value="%s">
+                <input type="hidden" name="action" value="create">
+                <input name="login" placeholder="Логин" required>
+                <input type="password" name="password" placeholder="Пароль" required>
+                %s
+                <button type="submit">Создать</button>
+            </form>
+        </div>`, rows, sess.csrf, roleSelect(RoleViewer))
+
+	a.showPage(w, "👥 Учетные записи", errorText, body)
+}
+
+func roleSelect(selected Role) string {
+	options := ""
+	for _, role := range []Role{RoleViewer, RoleOperator, RoleOwner} {
+		options += option(string(role), roleNames[role], string(selected))
+	}
+	return `<select name="role">` + options + `</select>`
+}
@@ -0,0 +1,246 @@
+package admin
+
+import (
+	"context"
+	"dailybot/internal/storage"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Сколько последних записей журнала держим в памяти и показываем в панели
+const auditCapacity = 1000
+
+// Действия, которые попадают в журнал
+const (
+	auditLogin           = "login"
+	auditLoginFailed     = "login_failed"
+	auditLogout          = "logout"
+	auditLimit           = "limit"
+	auditBan             = "ban"
+	auditUnban           = "unban"
+	auditBroadcast       = "broadcast"
+	auditAccountCreate   = "account_create"
+	auditAccountRole     = "account_role"
+	auditAccountPassword = "account_password"
+	auditAccountDelete   = "account_delete"
+	auditTokenCreate     = "token_create"
+	auditTokenRevoke     = "token_revoke"
+)
+
+var auditActionNames = map[string]string{
+	auditLogin:           "Вход",
+	auditLoginFailed:     "Неудачный вход",
+	auditLogout:          "Выход",
+	auditLimit:           "Изменение лимита",
+	auditBan:             "Блокировка",
+	auditUnban:           "Разблокировка",
+	auditBroadcast:       "Рассылка",
+	auditAccountCreate:   "Новая учетная запись",
+	auditAccountRole:     "Смена роли",
+	auditAccountPassword: "Смена пароля",
+	auditAccountDelete:   "Удаление учетной записи",
+	auditTokenCreate:     "Новый токен API",
+	auditTokenRevoke:     "Отзыв токена API",
+}
+
+// auditLog - журнал действий в админке. Последние записи живут в памяти,
+// при заданной базе все записи сохраняются в таблицу admin_audit
+type auditLog struct {
+	mu      sync.RWMutex
+	entries []storage.AuditEntry // старые - первыми
+}
+
+func (l *auditLog) add(entry storage.AuditEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = append(l.entries, entry)
+	if len(l.entries) > auditCapacity {
+		l.entries = append([]storage.AuditEntry(nil), l.entries[len(l.entries)-auditCapacity:]...)
+	}
+}
+
+// auditFilter - условия отбора записей на странице журнала
+type auditFilter struct {
+	Actor  string
+	Action string
+	Since  time.Time // нулевое - без ограничения
+}
+
+// find возвращает подходящие записи, новые - первыми
+func (l *auditLog) find(filter auditFilter) []storage.AuditEntry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var found []storage.AuditEntry
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		e := l.entries[i]
+		if filter.Actor != "" && e.Actor != filter.Actor {
+			continue
+		}
+		if filter.Action != "" && e.Action != filter.Action {
+			continue
+		}
+		if !filter.Since.IsZero() && e.CreatedAt.Before(filter.Since) {
+			continue
+		}
+		found = append(found, e)
+	}
+	return found
+}
+
+// actors - все, кто есть в журнале, для фильтра
+func (l *auditLog) actors() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var actors []string
+	for _, e := range l.entries {
+		if !seen[e.Actor] {
+			seen[e.Actor] = true
+			actors = append(actors, e.Actor)
+		}
+	}
+	sort.Strings(actors)
+	return actors
+}
+
+// audit записывает действие в журнал: кто, что и когда
+func (a *SimpleAdmin) audit(actor, action, details string) {
+	entry := storage.AuditEntry{Actor: actor, Action: action, Details: details, CreatedAt: time.Now()}
+	a.auditLog.add(entry)
+	log.Printf("📝 Audit: %s %s %s", actor, action, details)
+
+	if a.store == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := a.store.AddAuditEntry(ctx, entry); err != nil {
+		log.Printf("❌ Failed to save audit entry: %v", err)
+	}
+}
+
+// restoreAudit загружает последние записи журнала из базы
+func (a *SimpleAdmin) restoreAudit() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	entries, err := a.store.ListAuditEntries(ctx, auditCapacity)
+	if err != nil {
+		log.Printf("❌ Failed to restore audit log: %v", err)
+		return
+	}
+	for _, entry := range entries {
+		a.auditLog.add(entry)
+	}
+}
+
+// handleAudit - журнал действий с фильтрами по автору, действию и дате
+func (a *SimpleAdmin) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if _, _, ok := a.authorize(w, r, RoleOperator); !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := auditFilter{Actor: query.Get("actor"), Action: query.Get("action")}
+
+	errorText := ""
+	if since := query.Get("since"); since != "" {
+		day, err := time.ParseInLocation("2006-01-02", since, time.Local)
+		if err != nil {
+			errorText = "дата задается как ГГГГ-ММ-ДД"
+		} else {
+			filter.Since = day
+		}
+	}
+
+	a.showAudit(w, filter, errorText)
+}
+
+func (a *SimpleAdmin) showAudit(w http.ResponseWriter, filter auditFilter, errorText string) {
+	entries := a.auditLog.find(filter)
+
+	rows := ""
+	for _, e := range entries {
+		action := auditActionNames[e.Action]
+		if action == "" {
+			action = e.Action
+		}
+		rows += fmt.Sprintf("<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>",
+			e.CreatedAt.Format("02.01.2006 15:04:05"), html.EscapeString(e.Actor), action, html.EscapeString(e.Details))
+	}
+	if rows == "" {
+		rows = `<tr><td colspan="4" class="hint">Записей нет</td></tr>`
+	}
+
+	actorOptions := `<option value="">Все</option>`
+	for _, actor := range a.auditLog.actors() {
+		actorOptions += option(actor, html.EscapeString(actor), filter.Actor)
+	}
+
+	actions := make([]string, 0, len(auditActionNames))
+	for action := range auditActionNames {
+		actions = append(actions, action)
+	}
+	sort.Slice(actions, func(i, j int) bool { return auditActionNames[actions[i]] < auditActionNames[actions[j]] })
+
+	actionOptions := `<option value="">Все</option>`
+	for _, action := range actions {
+		actionOptions += option(action, auditActionNames[action], filter.Action)
+	}
+
+	since := ""
+	if !filter.Since.IsZero() {
+		since = filter.Since.Format("2006-01-02")
+	}
+
+	body := fmt.Sprintf(`<div class="info-card">
+            <form method="GET" class="filters">
+                <label>Кто <select name="actor">%s</select></label>
+                <label>Действие <select name="action">%s</select></label>
+                <label>С <input type="date" name="since" value="%s"></label>
+                <button type="submit">Показать</button>
+                <a href="/audit" class="hint">сбросить</a>
+            </form>
+        </div>
+
+        <div class="info-card">
+            <h3>Записи: %d</h3>
+            <table class="data-table">
+                <tr><th>Когда</th><th>Кто</th><th>Действие</th><th>Подробности</th></tr>
+                %s
+            </table>
+            <p class="hint">В панели показываются последние %d записей.</p>
+        </div>`, actorOptions, actionOptions, since, len(entries), rows, auditCapacity)
+
+	a.showPage(w, "📝 Журнал действий", errorText, body)
+}
+
+func option(value, label, selected string) string {
+	attr := ""
+	if value == selected {
+		attr = " selected"
+	}
+	return fmt.Sprintf(`<option value="%s"%s>%s</option>`, html.EscapeString(value), attr, label)
+}
+
+// auditDetails склеивает непустые подробности действия
+func auditDetails(parts ...string) string {
+	var nonEmpty []string
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, ", ")
+}
@@ -0,0 +1,133 @@
+package admin
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Telegram не принимает сообщения длиннее 4096 символов
+const maxBroadcastLength = 4096
+
+// Получатели рассылки
+const (
+	audienceAll    = "all"    // все чаты, которые писали боту
+	audienceActive = "active" // активные за последние сутки
+)
+
+// handleBroadcast - рассылка сообщения пользователям бота, для операторов
+func (a *SimpleAdmin) handleBroadcast(w http.ResponseWriter, r *http.Request) {
+	sess, account, ok := a.authorize(w, r, RoleOperator)
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		if !checkCSRF(r, sess) {
+			http.Error(w, "Invalid CSRF token", http.StatusForbidden)
+			return
+		}
+
+		target := "/broadcast"
+		count, err := a.startBroadcast(r, account)
+		if err != nil {
+			target += "?error=" + url.QueryEscape(err.Error())
+		} else {
+			target += fmt.Sprintf("?started=%d", count)
+		}
+		http.Redirect(w, r, target, http.StatusSeeOther)
+		return
+	}
+
+	a.showBroadcast(w, sess, r.URL.Query().Get("error"), r.URL.Query().Get("started"))
+}
+
+func (a *SimpleAdmin) startBroadcast(r *http.Request, actor Account) (int, error) {
+	text := strings.TrimSpace(r.FormValue("text"))
+	if text == "" {
+		return 0, fmt.Errorf("текст рассылки пустой")
+	}
+	if utf8.RuneCountInString(text) > maxBroadcastLength {
+		return 0, fmt.Errorf("текст длиннее %d символов", maxBroadcastLength)
+	}
+
+	audience := r.FormValue("audience")
+	if audience != audienceAll && audience != audienceActive {
+		return 0, fmt.Errorf("неизвестные получатели")
+	}
+
+	a.mu.RLock()
+	bot := a.bot
+	a.mu.RUnlock()
+	if bot == nil {
+		return 0, fmt.Errorf("бот еще не запущен")
+	}
+
+	chatIDs := a.broadcastRecipients(audience)
+	if len(chatIDs) == 0 {
+		return 0, fmt.Errorf("получателей нет")
+	}
+
+	// Текст отправляется как есть, без HTML-разметки
+	bot.Broadcast(html.EscapeString(text), chatIDs)
+
+	preview := text
+	if utf8.RuneCountInString(preview) > 100 {
+		preview = string([]rune(preview)[:97]) + "..."
+	}
+	a.audit(actor.Login, auditBroadcast, fmt.Sprintf("%d получателей (%s): %s", len(chatIDs), audience, preview))
+	return len(chatIDs), nil
+}
+
+// broadcastRecipients - чаты из статистики команд. Заблокированные пропускаются
+func (a *SimpleAdmin) broadcastRecipients(audience string) []int64 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	now := time.Now()
+	var chatIDs []int64
+	for chatID, lastSeen := range a.stats.ActiveUsers {
+		if audience == audienceActive && now.Sub(lastSeen) >= 24*time.Hour {
+			continue
+		}
+		if a.limiter.IsBanned(chatID) {
+			continue
+		}
+		chatIDs = append(chatIDs, chatID)
+	}
+	sort.Slice(chatIDs, func(i, j int) bool { return chatIDs[i] < chatIDs[j] })
+	return chatIDs
+}
+
+func (a *SimpleAdmin) showBroadcast(w http.ResponseWriter, sess session, errorText, started string) {
+	notice := ""
+	if started != "" {
+		notice = fmt.Sprintf(`<div class="success">Рассылка запущена: %s получателей. Итог будет в логе бота.</div>`, html.EscapeString(started))
+	}
+
+	body := fmt.Sprintf(`%s<div class="info-card">
+            <h3>Новая рассылка</h3>
+            <form method="POST" onsubmit="return confirm('Отправить сообщение всем выбранным получателям?')">
+                <input type="hidden" name="csrf" value="%s">
+                <textarea name="text" rows="8" maxlength="%d" placeholder="Текст сообщения" required></textarea>
+                <p>
+                    <select name="audience">
+                        <option value="%s">Активные за сутки (%d)</option>
+                        <option value="%s">Все, кто писал боту (%d)</option>
+                    </select>
+                    <button type="submit">Отправить</button>
+                </p>
+            </form>
+            <p class="hint">Сообщение уходит простым текстом, не быстрее 25 сообщений в секунду. Заблокированные чаты пропускаются.</p>
+        </div>`,
+		notice, sess.csrf, maxBroadcastLength,
+		audienceActive, len(a.broadcastRecipients(audienceActive)),
+		audienceAll, len(a.broadcastRecipients(audienceAll)))
+
+	a.showPage(w, "📢 Рассылка", errorText, body)
+}
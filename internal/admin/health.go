@@ -0,0 +1,186 @@
+package admin
+
+import (
+	"context"
+	"dailybot/internal/config"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Проверки Telegram и базы кешируются: /readyz открыт без авторизации,
+// и частые запросы к нему не должны превращаться в запросы к getMe
+const (
+	healthCacheTTL     = 15 * time.Second
+	healthCheckTimeout = 5 * time.Second
+)
+
+// Состояния отдельной проверки в ответе /readyz
+const (
+	checkOK          = "ok"
+	checkUnavailable = "unavailable"
+	checkStale       = "stale"
+	checkDisabled    = "disabled"
+)
+
+// healthReport - результат проверок готовности бота
+type healthReport struct {
+	Telegram string
+	Database string
+	Updates  string
+	LastPoll time.Time // нулевое - обновлений еще не запрашивали
+}
+
+func (r healthReport) ready() bool {
+	return r.Telegram == checkOK && r.Updates == checkOK &&
+		(r.Database == checkOK || r.Database == checkDisabled)
+}
+
+// problems - что не так, по-русски для панели
+func (r healthReport) problems() []string {
+	var problems []string
+	if r.Telegram != checkOK {
+		problems = append(problems, "нет связи с Telegram")
+	}
+	if r.Database == checkUnavailable {
+		problems = append(problems, "база данных недоступна")
+	}
+	switch r.Updates {
+	case checkStale:
+		problems = append(problems, "цикл получения обновлений завис")
+	case checkUnavailable:
+		problems = append(problems, "бот не запущен")
+	}
+	return problems
+}
+
+// healthChecker хранит последний результат проверок Telegram и базы
+type healthChecker struct {
+	mu        sync.Mutex
+	checkedAt time.Time
+	telegram  string
+	database  string
+}
+
+// health проверяет Telegram (getMe), базу, если она задана, и свежесть
+// цикла получения обновлений
+func (a *SimpleAdmin) health() healthReport {
+	a.mu.RLock()
+	bot := a.bot
+	a.mu.RUnlock()
+
+	report := healthReport{Updates: checkUnavailable}
+	if bot != nil {
+		last, stale := bot.PollStatus()
+		report.LastPoll = last
+		report.Updates = checkOK
+		if stale {
+			report.Updates = checkStale
+		}
+	}
+
+	a.checker.mu.Lock()
+	defer a.checker.mu.Unlock()
+
+	if time.Since(a.checker.checkedAt) > healthCacheTTL {
+		a.checker.telegram = a.checkTelegram(bot)
+		a.checker.database = a.checkDatabase()
+		a.checker.checkedAt = time.Now()
+	}
+	report.Telegram = a.checker.telegram
+	report.Database = a.checker.database
+
+	return report
+}
+
+func (a *SimpleAdmin) checkTelegram(bot BotStatus) string {
+	if bot == nil {
+		return checkUnavailable
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+
+	if err := bot.PingTelegram(ctx); err != nil {
+		log.Printf("❌ Health check: Telegram unavailable: %v", err)
+		return checkUnavailable
+	}
+	return checkOK
+}
+
+func (a *SimpleAdmin) checkDatabase() string {
+	if a.store == nil {
+		// База задана явно, но к ней не подключились при старте
+		if a.config.DatabaseConfigured {
+			return checkUnavailable
+		}
+		return checkDisabled
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+
+	if err := a.store.Ping(ctx); err != nil {
+		log.Printf("❌ Health check: database unavailable: %v", err)
+		return checkUnavailable
+	}
+	return checkOK
+}
+
+// handleHealthz - живость: процесс отвечает на HTTP
+func (a *SimpleAdmin) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write([]byte(`{"status": "ok"}`))
+}
+
+// handleReadyz - готовность: 200, если бот может обрабатывать обновления,
+// иначе 503. Подробности ошибок только в логе: в них может быть токен бота
+func (a *SimpleAdmin) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	report := a.health()
+
+	status, code := "ok", http.StatusOK
+	if !report.ready() {
+		status, code = "unavailable", http.StatusServiceUnavailable
+	}
+
+	var lastPoll *time.Time
+	if !report.LastPoll.IsZero() {
+		lastPoll = &report.LastPoll
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]any{
+		"status": status,
+		"checks": map[string]string{
+			"telegram": report.Telegram,
+			"database": report.Database,
+			"updates":  report.Updates,
+		},
+		"lastPoll": lastPoll,
+	})
+}
+
+// renderHealth - статус бота для панели
+func (a *SimpleAdmin) renderHealth(report healthReport) string {
+	status := `<span class="status ok">Онлайн и работает</span>`
+	if problems := report.problems(); len(problems) > 0 {
+		status = `<span class="status fail">` + strings.Join(problems, ", ") + `</span>`
+	}
+
+	source := "Последний опрос Telegram"
+	if a.config.BotMode == config.ModeWebhook {
+		source = "Последнее обновление"
+	}
+	last := "еще не было"
+	if !report.LastPoll.IsZero() {
+		last = report.LastPoll.Format("02.01.2006 15:04:05")
+	}
+
+	return status + `<br><small style="color: #666;">` + source + `: ` + last + `</small>`
+}
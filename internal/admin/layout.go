@@ -0,0 +1,101 @@
+package admin
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+)
+
+// showPage - страница раздела панели: заголовок, ссылка назад, ошибка и содержимое
+func (a *SimpleAdmin) showPage(w http.ResponseWriter, title, errorText, body string) {
+	notice := ""
+	if errorText != "" {
+		notice = `<div class="error">` + html.EscapeString(errorText) + `</div>`
+	}
+
+	page := fmt.Sprintf(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>DailyBot Admin - %s</title>
+    <style>
+        * { margin: 0; padding: 0; box-sizing: border-box; }
+        body {
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, Arial, sans-serif;
+            background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%);
+            min-height: 100vh;
+            padding: 20px;
+        }
+        .container { max-width: 1200px; margin: 0 auto; }
+        .header {
+            color: white;
+            padding: 20px;
+            margin-bottom: 20px;
+            display: flex;
+            justify-content: space-between;
+            align-items: center;
+        }
+        .btn {
+            background: rgba(255, 255, 255, 0.2);
+            color: white;
+            padding: 10px 20px;
+            border-radius: 8px;
+            text-decoration: none;
+        }
+        .info-card {
+            background: rgba(255, 255, 255, 0.95);
+            border-radius: 15px;
+            padding: 25px;
+            margin-bottom: 20px;
+            box-shadow: 0 10px 30px rgba(0, 0, 0, 0.1);
+        }
+        h3 { margin-bottom: 15px; }
+        .data-table { width: 100%%; border-collapse: collapse; margin-bottom: 15px; }
+        .data-table th, .data-table td {
+            text-align: left;
+            padding: 8px 12px;
+            border-bottom: 1px solid #e2e8f0;
+        }
+        .data-table th { color: #666; font-weight: 500; }
+        .data-table .actions form { display: inline-block; margin: 2px 8px 2px 0; }
+        input, select { padding: 6px 8px; border: 1px solid #e2e8f0; border-radius: 6px; width: 110px; }
+        select { width: auto; }
+        textarea {
+            width: 100%%;
+            padding: 10px;
+            border: 1px solid #e2e8f0;
+            border-radius: 6px;
+            font: inherit;
+            margin-bottom: 10px;
+        }
+        button {
+            padding: 6px 14px;
+            background: #667eea;
+            color: white;
+            border: none;
+            border-radius: 6px;
+            cursor: pointer;
+        }
+        button.danger { background: #dc2626; }
+        .filters { display: flex; gap: 15px; align-items: center; flex-wrap: wrap; }
+        .hint { color: #666; font-size: 14px; }
+        .error { background: #fee2e2; color: #991b1b; padding: 12px; border-radius: 8px; margin-bottom: 20px; }
+        .success { background: #dcfce7; color: #166534; padding: 12px; border-radius: 8px; margin-bottom: 20px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>%s</h1>
+            <a href="/" class="btn">← Панель</a>
+        </div>
+        %s
+        %s
+    </div>
+</body>
+</html>`, title, title, notice, body)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, page)
+}
@@ -0,0 +1,226 @@
+package admin
+
+import (
+	"context"
+	"dailybot/internal/ratelimit"
+	"dailybot/internal/storage"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var commandNameRe = regexp.MustCompile(`^([a-z_]{1,32}|\*)$`)
+
+// handleLimits - страница лимитов: GET показывает, POST меняет лимит или блокировку.
+// Блокируют операторы, лимиты меняет только владелец
+func (a *SimpleAdmin) handleLimits(w http.ResponseWriter, r *http.Request) {
+	sess, account, ok := a.authorize(w, r, RoleOperator)
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		if !checkCSRF(r, sess) {
+			http.Error(w, "Invalid CSRF token", http.StatusForbidden)
+			return
+		}
+
+		target := "/limits"
+		if err := a.applyLimitsForm(r, account); err != nil {
+			target += "?error=" + url.QueryEscape(err.Error())
+		}
+		http.Redirect(w, r, target, http.StatusSeeOther)
+		return
+	}
+
+	a.showLimits(w, sess, account, r.URL.Query().Get("error"))
+}
+
+func (a *SimpleAdmin) applyLimitsForm(r *http.Request, actor Account) error {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	switch r.FormValue("action") {
+	case "command", "provider":
+		if !actor.Role.allows(RoleOwner) {
+			return fmt.Errorf("лимиты может менять только владелец")
+		}
+		kind := storage.LimitCommand
+		if r.FormValue("action") == "provider" {
+			kind = storage.LimitProvider
+		}
+		return a.setLimit(ctx, actor, kind, strings.TrimSpace(r.FormValue("name")), r.FormValue("requests"), r.FormValue("window"))
+
+	case "ban":
+		id, err := strconv.ParseInt(strings.TrimSpace(r.FormValue("id")), 10, 64)
+		if err != nil || id == 0 {
+			return fmt.Errorf("неверный ID чата")
+		}
+		ban := a.limiter.Ban(id, strings.TrimSpace(r.FormValue("reason")))
+		log.Printf("🚫 Chat %d banned from admin panel", id)
+		a.audit(actor.Login, auditBan, auditDetails(strconv.FormatInt(id, 10), ban.Reason))
+
+		if a.store != nil {
+			if err := a.store.SaveBan(ctx, storage.Ban{ChatID: ban.ID, Reason: ban.Reason, CreatedAt: ban.Since}); err != nil {
+				log.Printf("❌ Failed to save ban: %v", err)
+				return fmt.Errorf("блокировка действует до перезапуска: не удалось сохранить в базу")
+			}
+		}
+		return nil
+
+	case "unban":
+		id, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+		if err != nil || !a.limiter.Unban(id) {
+			return fmt.Errorf("блокировка не найдена")
+		}
+		log.Printf("✅ Chat %d unbanned from admin panel", id)
+		a.audit(actor.Login, auditUnban, strconv.FormatInt(id, 10))
+
+		if a.store != nil {
+			if err := a.store.DeleteBan(ctx, id); err != nil {
+				log.Printf("❌ Failed to delete ban: %v", err)
+				return fmt.Errorf("блокировка снята до перезапуска: не удалось удалить из базы")
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("неизвестное действие")
+}
+
+func (a *SimpleAdmin) setLimit(ctx context.Context, actor Account, kind, name, requestsValue, windowValue string) error {
+	requests, err := strconv.Atoi(requestsValue)
+	if err != nil || requests < 1 {
+		return fmt.Errorf("число запросов должно быть положительным")
+	}
+	window, err := time.ParseDuration(windowValue)
+	if err != nil || window < time.Second {
+		return fmt.Errorf("окно задается как 30s, 1m или 24h")
+	}
+	limit := ratelimit.Limit{Requests: requests, Window: window}
+
+	if kind == storage.LimitProvider {
+		err = a.limiter.SetProviderLimit(name, limit)
+	} else {
+		if !commandNameRe.MatchString(name) {
+			return fmt.Errorf("неверное имя команды")
+		}
+		err = a.limiter.SetCommandLimit(name, limit)
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("🚦 Rate limit %s %s set to %s", kind, name, limit)
+	a.audit(actor.Login, auditLimit, fmt.Sprintf("%s %s: %s", kind, name, limit))
+
+	if a.store != nil {
+		if err := a.store.SaveRateLimit(ctx, storage.RateLimit{Kind: kind, Name: name, Requests: requests, Window: window}); err != nil {
+			log.Printf("❌ Failed to save rate limit: %v", err)
+			return fmt.Errorf("лимит действует до перезапуска: не удалось сохранить в базу")
+		}
+	}
+	return nil
+}
+
+func (a *SimpleAdmin) showLimits(w http.ResponseWriter, sess session, account Account, errorText string) {
+	// Лимиты меняет только владелец, оператор их только видит
+	readOnly := !account.Role.allows(RoleOwner)
+
+	body := fmt.Sprintf(`<div class="info-card">
+            <h3>Лимиты команд на пользователя</h3>
+            %s
+            <p class="hint">Команды без своего лимита делят общий лимит «*». Кнопки считаются в лимит своей команды.</p>
+        </div>
+
+        <div class="info-card">
+            <h3>Бюджет запросов к внешним API</h3>
+            %s
+            <p class="hint">Общий на всех пользователей. Когда бюджет исчерпан, бот отвечает из кеша, если данные там есть.</p>
+        </div>
+
+        <div class="info-card">
+            <h3>Заблокированные чаты</h3>
+            %s
+            <form method="POST">
+                <input type="hidden" name="csrf" value="%s">
+                <input type="hidden" name="action" value="ban">
+                <input name="id" placeholder="ID чата" required>
+                <input name="reason" placeholder="Причина" style="width: 260px;">
+                <button type="submit">Заблокировать</button>
+            </form>
+        </div>`,
+		renderLimits("command", a.limiter.CommandLimits(), true, readOnly, sess.csrf),
+		renderLimits("provider", a.limiter.ProviderLimits(), false, readOnly, sess.csrf),
+		renderBans(a.limiter.Bans(), sess.csrf),
+		sess.csrf,
+	)
+
+	a.showPage(w, "🚦 Лимиты и блокировки", errorText, body)
+}
+
+// renderLimits - таблица лимитов с формой изменения в каждой строке.
+// Форма не может охватывать ячейки таблицы, поэтому поля привязаны к ней атрибутом form.
+// Для команд добавляется строка, в которой можно задать лимит новой команде.
+// readOnly - только значения, без форм
+func renderLimits(action string, limits []ratelimit.LimitInfo, editableName, readOnly bool, csrf string) string {
+	row := func(i int, name, requests, window, available string) string {
+		if readOnly {
+			return fmt.Sprintf("<tr><td>%s</td>%s<td>%s</td><td>%s</td><td></td></tr>",
+				html.EscapeString(name), available, requests, window)
+		}
+
+		id := fmt.Sprintf("%s-%d", action, i)
+
+		nameCell := fmt.Sprintf(`<input form="%s" name="name" placeholder="команда" required>`, id)
+		if name != "" {
+			nameCell = html.EscapeString(name) + fmt.Sprintf(`<input form="%s" type="hidden" name="name" value="%s">`, id, html.EscapeString(name))
+		}
+
+		return fmt.Sprintf(`<tr><td>%s</td>%s<td><input form="%s" name="requests" value="%s" required></td>
+                <td><input form="%s" name="window" value="%s" required></td>
+                <td><form id="%s" method="POST"><input type="hidden" name="csrf" value="%s"><input type="hidden" name="action" value="%s"><button type="submit">Сохранить</button></form></td></tr>`,
+			nameCell, available, id, requests, id, window, id, csrf, action)
+	}
+
+	header := "<tr><th>Команда</th><th>Запросов</th><th>За период</th><th></th></tr>"
+	if !editableName {
+		header = "<tr><th>Провайдер</th><th>Осталось</th><th>Запросов</th><th>За период</th><th></th></tr>"
+	}
+
+	rows := ""
+	for i, l := range limits {
+		available := ""
+		if !editableName {
+			available = fmt.Sprintf("<td>%d</td>", l.Available)
+		}
+		rows += row(i, l.Name, strconv.Itoa(l.Limit.Requests), l.Limit.Window.String(), available)
+	}
+	if editableName && !readOnly {
+		rows += row(len(limits), "", "", "1m0s", "")
+	}
+
+	return `<table class="data-table">` + header + rows + `</table>`
+}
+
+func renderBans(bans []ratelimit.Ban, csrf string) string {
+	if len(bans) == 0 {
+		return `<p class="hint" style="margin-bottom: 15px;">Блокировок нет</p>`
+	}
+
+	rows := ""
+	for _, ban := range bans {
+		rows += fmt.Sprintf(`<tr><td>%d</td><td>%s</td><td>%s</td><td><form method="POST">
+                <input type="hidden" name="csrf" value="%s">
+                <input type="hidden" name="action" value="unban"><input type="hidden" name="id" value="%d">
+                <button type="submit">Разблокировать</button></form></td></tr>`,
+			ban.ID, html.EscapeString(ban.Reason), ban.Since.Format("02.01.2006 15:04"), csrf, ban.ID)
+	}
+
+	return `<table class="data-table"><tr><th>ID</th><th>Причина</th><th>С</th><th></th></tr>` + rows + `</table>`
+}
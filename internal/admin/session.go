@@ -0,0 +1,271 @@
+package admin
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	sessionCookie   = "admin_session"
+	loginCSRFCookie = "admin_login_csrf"
+	sessionTTL      = 24 * time.Hour
+
+	// После loginMaxFailures неудачных попыток за loginFailureWindow
+	// вход под этим логином с этого адреса блокируется на loginLockout
+	loginMaxFailures   = 5
+	loginFailureWindow = 15 * time.Minute
+	loginLockout       = 15 * time.Minute
+)
+
+// session - вход в панель. Живет только в памяти: после рестарта
+// нужно войти заново
+type session struct {
+	login   string // учетная запись; права проверяются по ней на каждый запрос
+	csrf    string // токен для POST-форм этой сессии
+	expires time.Time
+}
+
+// sessionStore хранит сессии на сервере, в куке - только случайный ID
+type sessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]session
+}
+
+func newSessionStore() *sessionStore {
+	return &sessionStore{sessions: make(map[string]session)}
+}
+
+// create открывает новую сессию учетной записи и возвращает ее ID
+func (s *sessionStore) create(login string) (string, session, error) {
+	id, err := randomToken()
+	if err != nil {
+		return "", session{}, err
+	}
+	csrf, err := randomToken()
+	if err != nil {
+		return "", session{}, err
+	}
+
+	sess := session{login: login, csrf: csrf, expires: time.Now().Add(sessionTTL)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked(time.Now())
+	s.sessions[id] = sess
+	return id, sess, nil
+}
+
+// get возвращает живую сессию. Истекшая удаляется
+func (s *sessionStore) get(id string) (session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[id]
+	if !ok {
+		return session{}, false
+	}
+	if time.Now().After(sess.expires) {
+		delete(s.sessions, id)
+		return session{}, false
+	}
+	return sess, true
+}
+
+func (s *sessionStore) delete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+}
+
+// deleteLogin закрывает все сессии учетной записи
+func (s *sessionStore) deleteLogin(login string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, sess := range s.sessions {
+		if sess.login == login {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+func (s *sessionStore) pruneLocked(now time.Time) {
+	for id, sess := range s.sessions {
+		if now.After(sess.expires) {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+// loginThrottle считает неудачные попытки входа по паре логин+IP. Только по
+// IP считать нельзя: за reverse proxy у всех админов один адрес, и чужие
+// ошибки блокировали бы всех
+type loginThrottle struct {
+	mu       sync.Mutex
+	failures map[string]*loginFailures
+	// unknown - попытки входа под несуществующими логинами по IP. Перебор
+	// логинов не упирается в блокировку, поэтому в журнал пишется только
+	// первая попытка за loginFailureWindow, остальные лишь считаются
+	unknown map[string]*loginFailures
+}
+
+type loginFailures struct {
+	count       int
+	first       time.Time
+	lockedUntil time.Time
+}
+
+func newLoginThrottle() *loginThrottle {
+	return &loginThrottle{
+		failures: make(map[string]*loginFailures),
+		unknown:  make(map[string]*loginFailures),
+	}
+}
+
+// throttleKey - ключ счетчика попыток для логина с адреса
+func throttleKey(login, ip string) string {
+	return login + "|" + ip
+}
+
+// locked - сколько еще ждать, если вход заблокирован
+func (t *loginThrottle) locked(key string) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	f, ok := t.failures[key]
+	if !ok {
+		return 0, false
+	}
+	if wait := time.Until(f.lockedUntil); wait > 0 {
+		return wait, true
+	}
+	return 0, false
+}
+
+// fail записывает неудачную попытку. true - вход только что заблокирован
+func (t *loginThrottle) fail(key string) bool {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pruneLocked(now)
+
+	f, ok := t.failures[key]
+	if !ok {
+		f = &loginFailures{first: now}
+		t.failures[key] = f
+	}
+
+	f.count++
+	if f.count >= loginMaxFailures {
+		f.lockedUntil = now.Add(loginLockout)
+		f.count = 0
+		f.first = now
+		return true
+	}
+	return false
+}
+
+// failUnknown записывает попытку входа под несуществующим логином с адреса.
+// audit - попытку нужно записать в журнал, skipped - сколько попыток с этого
+// адреса за прошлое окно в журнал не попало
+func (t *loginThrottle) failUnknown(ip string) (audit bool, skipped int) {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pruneLocked(now)
+
+	f, ok := t.unknown[ip]
+	if ok && now.Sub(f.first) <= loginFailureWindow {
+		f.count++
+		return false, 0
+	}
+	if ok {
+		skipped = f.count
+	}
+	t.unknown[ip] = &loginFailures{first: now}
+	return true, skipped
+}
+
+// pruneLocked чистит старые записи по ходу, чтобы перебор с разных адресов
+// не копил память. Записи о несуществующих логинах с непопавшими в журнал
+// попытками живут еще одно окно, чтобы их число досталось следующей записи
+func (t *loginThrottle) pruneLocked(now time.Time) {
+	for k, f := range t.failures {
+		if now.Sub(f.first) > loginFailureWindow && now.After(f.lockedUntil) {
+			delete(t.failures, k)
+		}
+	}
+	for ip, f := range t.unknown {
+		if now.Sub(f.first) > 2*loginFailureWindow || (f.count == 0 && now.Sub(f.first) > loginFailureWindow) {
+			delete(t.unknown, ip)
+		}
+	}
+}
+
+func (t *loginThrottle) reset(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.failures, key)
+}
+
+// randomToken - 32 случайных байта в base64url
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func tokensEqual(a, b string) bool {
+	return a != "" && subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// clientIP - адрес клиента для ограничения попыток входа. X-Forwarded-For
+// не учитывается: его может подделать кто угодно
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// secureRequest - запрос пришел по HTTPS напрямую или через reverse proxy
+func secureRequest(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
+
+func setCookie(w http.ResponseWriter, r *http.Request, name, value string, maxAge time.Duration) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(maxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   secureRequest(r),
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+func clearCookie(w http.ResponseWriter, r *http.Request, name string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   secureRequest(r),
+		SameSite: http.SameSiteStrictMode,
+	})
+}
@@ -1,19 +1,60 @@
 package admin
 
 import (
+	"context"
+	"dailybot/internal/api"
 	"dailybot/internal/config"
+	"dailybot/internal/metrics"
+	"dailybot/internal/ratelimit"
+	"dailybot/internal/storage"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
 	"log"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
+const statsFlushInterval = 10 * time.Second
+
+// BotStatus - бот со стороны панели: состояние и рассылка
+type BotStatus interface {
+	QueueStats() (queued, inFlight, capacity int)
+	// PollStatus - время последнего запроса обновлений и не завис ли цикл
+	PollStatus() (last time.Time, stale bool)
+	// PingTelegram проверяет доступность Bot API
+	PingTelegram(ctx context.Context) error
+	// Broadcast отправляет HTML-сообщение в чаты в фоне
+	Broadcast(text string, chatIDs []int64)
+}
+
 type SimpleAdmin struct {
 	config    *config.Config
+	store     *storage.Storage // nil - статистика хранится только в памяти
+	limiter   *ratelimit.Limiter
+	bot       BotStatus
+	server    *http.Server
+	sessions  *sessionStore
+	logins    *loginThrottle
+	accounts  *accountStore
+	tokens    *tokenStore
+	auditLog  auditLog
+	checker   healthChecker
 	stats     Stats
 	mu        sync.RWMutex
 	startTime time.Time
+
+	// Изменения статистики, еще не записанные в базу
+	pendingMessages int64
+	pendingCommands map[string]int64
+	pendingUsers    map[int64]time.Time
+	stopFlush       chan struct{}
+	flushDone       chan struct{}
 }
 
 type Stats struct {
@@ -24,37 +65,166 @@ type Stats struct {
 	ActiveUsers      map[int64]time.Time
 }
 
-func NewSimpleAdmin(cfg *config.Config) *SimpleAdmin {
-	return &SimpleAdmin{
+func NewSimpleAdmin(cfg *config.Config, store *storage.Storage, limiter *ratelimit.Limiter) *SimpleAdmin {
+	// Владелец из конфига есть всегда, остальные учетные записи создаются в панели
+	owner := Account{
+		Login:        cfg.AdminLogin,
+		PasswordHash: cfg.AdminPasswordHash,
+		Role:         RoleOwner,
+		CreatedAt:    time.Now(),
+		Builtin:      true,
+	}
+
+	a := &SimpleAdmin{
 		config:    cfg,
+		store:     store,
+		limiter:   limiter,
+		sessions:  newSessionStore(),
+		logins:    newLoginThrottle(),
+		accounts:  newAccountStore(owner),
+		tokens:    newTokenStore(),
 		startTime: time.Now(),
 		stats: Stats{
 			ActiveUsers: make(map[int64]time.Time),
 		},
+		pendingCommands: make(map[string]int64),
+		pendingUsers:    make(map[int64]time.Time),
+		stopFlush:       make(chan struct{}),
+		flushDone:       make(chan struct{}),
+	}
+
+	// Все /api/* - только с сессией панели или токеном
+	apiMux := http.NewServeMux()
+	apiMux.HandleFunc("/api/stats", a.handleStats)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", a.handleAdmin)
+	mux.HandleFunc("/logout", a.handleLogout)
+	mux.HandleFunc("/healthz", a.handleHealthz)
+	mux.HandleFunc("/readyz", a.handleReadyz)
+	mux.Handle("/api/", a.apiAuth(apiMux))
+	mux.HandleFunc("/limits", a.handleLimits)
+	mux.HandleFunc("/broadcast", a.handleBroadcast)
+	mux.HandleFunc("/accounts", a.handleAccounts)
+	mux.HandleFunc("/audit", a.handleAudit)
+	mux.HandleFunc("/tokens", a.handleTokens)
+
+	// Слушаем на всех интерфейсах (важно для Docker)
+	a.server = &http.Server{
+		Addr:              "0.0.0.0:" + cfg.AdminPort,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	metrics.SetUsersSource(a.userCounts)
+
+	if store != nil {
+		a.restoreStats()
+		a.restoreAccounts()
+		a.restoreAudit()
+		a.restoreTokens()
+		go a.runFlusher()
+	} else {
+		close(a.flushDone)
 	}
+
+	return a
 }
 
-func (a *SimpleAdmin) Start() {
-	http.HandleFunc("/", a.handleAdmin)
-	http.HandleFunc("/api/stats", a.handleStats)
+// restoreStats восстанавливает счетчики из базы, чтобы после рестарта
+// панель показывала статистику за все время, а не с момента деплоя
+func (a *SimpleAdmin) restoreStats() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	saved, err := a.store.LoadStats(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to restore stats: %v", err)
+		return
+	}
 
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.stats.TotalMessages = saved.Messages
+	for command, count := range saved.Commands {
+		switch command {
+		case "weather":
+			a.stats.WeatherRequests = count
+		case "news":
+			a.stats.NewsRequests = count
+		case "exchange":
+			a.stats.ExchangeRequests = count
+		}
+	}
+	a.stats.ActiveUsers = saved.ActiveUsers
+
+	log.Printf("📊 Stats restored: %d messages, %d users", a.stats.TotalMessages, len(a.stats.ActiveUsers))
+}
+
+// userCounts - чаты, активные за сутки, и все чаты, которые писали боту
+func (a *SimpleAdmin) userCounts() (active, total int) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	now := time.Now()
+	for _, lastSeen := range a.stats.ActiveUsers {
+		if now.Sub(lastSeen) < 24*time.Hour {
+			active++
+		}
+	}
+	return active, len(a.stats.ActiveUsers)
+}
+
+// AttachBot подключает бота, чтобы показывать его состояние в панели
+func (a *SimpleAdmin) AttachBot(bot BotStatus) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.bot = bot
+}
+
+func (a *SimpleAdmin) queueStats() (queued, inFlight, capacity int) {
+	a.mu.RLock()
+	bot := a.bot
+	a.mu.RUnlock()
+
+	if bot == nil {
+		return 0, 0, 0
+	}
+	return bot.QueueStats()
+}
+
+func (a *SimpleAdmin) Start() {
 	port := a.config.AdminPort
 	log.Printf("🎛 Admin panel: http://localhost:%s", port)
-	log.Printf("🔑 Password: %s", a.config.AdminPassword)
 	log.Printf("📡 Listening on 0.0.0.0:%s", port)
 
-	// Слушаем на всех интерфейсах (важно для Docker)
-	if err := http.ListenAndServe("0.0.0.0:"+port, nil); err != nil {
+	if err := a.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Printf("❌ Failed to start admin server: %v", err)
 	}
 }
 
+// Shutdown останавливает HTTP-сервер, дожидаясь активных запросов,
+// и записывает в базу накопленную статистику
+func (a *SimpleAdmin) Shutdown(ctx context.Context) error {
+	err := a.server.Shutdown(ctx)
+
+	if a.store != nil {
+		close(a.stopFlush)
+		<-a.flushDone
+	}
+
+	return err
+}
+
 func (a *SimpleAdmin) LogCommand(userID int64, command, args string) {
+	now := time.Now()
+
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
 	a.stats.TotalMessages++
-	a.stats.ActiveUsers[userID] = time.Now()
+	a.stats.ActiveUsers[userID] = now
 
 	switch command {
 	case "weather":
@@ -64,49 +234,213 @@ func (a *SimpleAdmin) LogCommand(userID int64, command, args string) {
 	case "exchange":
 		a.stats.ExchangeRequests++
 	}
+
+	if a.store != nil {
+		a.pendingMessages++
+		a.pendingCommands[command]++
+		a.pendingUsers[userID] = now
+	}
 }
 
-func (a *SimpleAdmin) handleAdmin(w http.ResponseWriter, r *http.Request) {
-	// Простая авторизация через форму
-	if r.Method == "POST" {
-		password := r.FormValue("password")
-		if password == a.config.AdminPassword {
-			// Устанавливаем куку на 24 часа
-			http.SetCookie(w, &http.Cookie{
-				Name:     "admin_auth",
-				Value:    a.config.AdminPassword,
-				Expires:  time.Now().Add(24 * time.Hour),
-				HttpOnly: true,
-			})
-			http.Redirect(w, r, "/", http.StatusSeeOther)
+// runFlusher пачками записывает статистику в базу, чтобы не делать
+// транзакцию на каждое сообщение. Последняя запись - при остановке.
+func (a *SimpleAdmin) runFlusher() {
+	defer close(a.flushDone)
+
+	ticker := time.NewTicker(statsFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			a.flushStats()
+		case <-a.stopFlush:
+			a.flushStats()
 			return
 		}
 	}
+}
+
+func (a *SimpleAdmin) flushStats() {
+	a.mu.Lock()
+	messages, commands, users := a.pendingMessages, a.pendingCommands, a.pendingUsers
+	if messages == 0 && len(commands) == 0 && len(users) == 0 {
+		a.mu.Unlock()
+		return
+	}
+	a.pendingMessages = 0
+	a.pendingCommands = make(map[string]int64)
+	a.pendingUsers = make(map[int64]time.Time)
+	a.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := a.store.SaveStats(ctx, messages, commands, users); err != nil {
+		log.Printf("❌ Failed to persist command stats: %v", err)
+
+		// Возвращаем несохраненное, чтобы записать со следующей попыткой
+		a.mu.Lock()
+		a.pendingMessages += messages
+		for command, count := range commands {
+			a.pendingCommands[command] += count
+		}
+		for userID, lastSeen := range users {
+			if lastSeen.After(a.pendingUsers[userID]) {
+				a.pendingUsers[userID] = lastSeen
+			}
+		}
+		a.mu.Unlock()
+	}
+}
+
+func (a *SimpleAdmin) handleAdmin(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		a.handleLogin(w, r)
+		return
+	}
 
 	// Проверяем авторизацию
-	cookie, err := r.Cookie("admin_auth")
-	if err != nil || cookie.Value != a.config.AdminPassword {
-		a.showLoginForm(w)
+	sess, account, ok := a.currentAccount(r)
+	if !ok {
+		a.showLoginForm(w, r, http.StatusOK, "")
 		return
 	}
 
-	// Проверяем logout
-	if r.URL.Query().Get("logout") == "1" {
-		http.SetCookie(w, &http.Cookie{
-			Name:     "admin_auth",
-			Value:    "",
-			Expires:  time.Now().Add(-time.Hour),
-			HttpOnly: true,
-		})
-		http.Redirect(w, r, "/", http.StatusSeeOther)
+	a.showDashboard(w, sess, account)
+}
+
+// handleLogin проверяет пароль из формы входа и открывает сессию
+func (a *SimpleAdmin) handleLogin(w http.ResponseWriter, r *http.Request) {
+	ip := clientIP(r)
+	login := strings.ToLower(strings.TrimSpace(r.FormValue("login")))
+	throttle := throttleKey(login, ip)
+
+	if wait, locked := a.logins.locked(throttle); locked {
+		a.showLoginForm(w, r, http.StatusTooManyRequests,
+			fmt.Sprintf("Слишком много попыток. Повторите через %d мин.", int(wait.Minutes())+1))
+		return
+	}
+
+	// Форма входа защищена double-submit токеном: он лежит и в куке, и в форме
+	cookie, err := r.Cookie(loginCSRFCookie)
+	if err != nil || !tokensEqual(r.FormValue("csrf"), cookie.Value) {
+		a.showLoginForm(w, r, http.StatusForbidden, "Форма устарела, попробуйте еще раз")
+		return
+	}
+
+	account, exists := a.accounts.get(login)
+
+	// Для несуществующего логина хеш все равно проверяется, чтобы по времени
+	// ответа нельзя было узнать, какие логины есть
+	hash := account.PasswordHash
+	if !exists {
+		hash = a.config.AdminPasswordHash
+	}
+
+	password := r.FormValue("password")
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil || !exists {
+		log.Printf("⚠️ Failed admin login from %s", ip)
+		if exists {
+			a.audit(login, auditLoginFailed, "IP "+ip)
+		} else if audit, skipped := a.logins.failUnknown(ip); audit {
+			details := "IP " + ip
+			if skipped > 0 {
+				details += fmt.Sprintf(", еще %d попыток с несуществующими логинами не записано", skipped)
+			}
+			a.audit("—", auditLoginFailed, details)
+		}
+		if a.logins.fail(throttle) {
+			log.Printf("🚨 Admin login %q from %s locked for %s after %d failed attempts", login, ip, loginLockout, loginMaxFailures)
+		}
+		a.showLoginForm(w, r, http.StatusUnauthorized, "Неверный логин или пароль")
+		return
+	}
+
+	id, _, err := a.sessions.create(login)
+	if err != nil {
+		log.Printf("❌ Failed to create admin session: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
+	a.logins.reset(throttle)
+	a.audit(login, auditLogin, "IP "+ip)
 
-	a.showDashboard(w)
+	setCookie(w, r, sessionCookie, id, sessionTTL)
+	clearCookie(w, r, loginCSRFCookie)
+	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
 
-func (a *SimpleAdmin) showLoginForm(w http.ResponseWriter) {
-	html := `<!DOCTYPE html>
+func (a *SimpleAdmin) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if sess, account, ok := a.currentAccount(r); ok && checkCSRF(r, sess) {
+		cookie, _ := r.Cookie(sessionCookie)
+		a.sessions.delete(cookie.Value)
+		a.audit(account.Login, auditLogout, "")
+	}
+
+	clearCookie(w, r, sessionCookie)
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// currentAccount - сессия из куки запроса и ее учетная запись. Сессия
+// удаленной учетной записи не действует
+func (a *SimpleAdmin) currentAccount(r *http.Request) (session, Account, bool) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return session{}, Account{}, false
+	}
+	sess, ok := a.sessions.get(cookie.Value)
+	if !ok {
+		return session{}, Account{}, false
+	}
+	account, ok := a.accounts.get(sess.login)
+	if !ok {
+		return session{}, Account{}, false
+	}
+	return sess, account, true
+}
+
+// authorize пускает на страницу учетные записи с ролью не ниже required.
+// Без входа - перенаправляет на форму входа, без прав - 403
+func (a *SimpleAdmin) authorize(w http.ResponseWriter, r *http.Request, required Role) (session, Account, bool) {
+	sess, account, ok := a.currentAccount(r)
+	if !ok {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return session{}, Account{}, false
+	}
+	if !account.Role.allows(required) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return session{}, Account{}, false
+	}
+	return sess, account, true
+}
+
+// checkCSRF сверяет токен из POST-формы с токеном сессии
+func checkCSRF(r *http.Request, sess session) bool {
+	return tokensEqual(r.FormValue("csrf"), sess.csrf)
+}
+
+// showLoginForm показывает форму входа с новым CSRF-токеном
+func (a *SimpleAdmin) showLoginForm(w http.ResponseWriter, r *http.Request, status int, errorText string) {
+	token, err := randomToken()
+	if err != nil {
+		log.Printf("❌ Failed to generate CSRF token: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	setCookie(w, r, loginCSRFCookie, token, time.Hour)
+
+	notice := ""
+	if errorText != "" {
+		notice = `<div class="error">` + html.EscapeString(errorText) + `</div>`
+	}
+
+	page := fmt.Sprintf(`<!DOCTYPE html>
 <html lang="ru">
 <head>
     <meta charset="UTF-8">
@@ -116,7 +450,7 @@ func (a *SimpleAdmin) showLoginForm(w http.ResponseWriter) {
         * { margin: 0; padding: 0; box-sizing: border-box; }
         body {
             font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, Arial, sans-serif;
-            background: linear-gradient(135deg, #667eea 0%, #764ba2 100%);
+            background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%);
             min-height: 100vh;
             display: flex;
             align-items: center;
@@ -129,13 +463,13 @@ func (a *SimpleAdmin) showLoginForm(w http.ResponseWriter) {
             box-shadow: 0 15px 35px rgba(0, 0, 0, 0.1);
             padding: 40px;
             max-width: 400px;
-            width: 90%;
+            width: 90%%;
             text-align: center;
         }
         .logo { font-size: 3rem; margin-bottom: 10px; }
         h1 { color: #333; margin-bottom: 30px; font-size: 1.8rem; }
         input {
-            width: 100%;
+            width: 100%%;
             padding: 15px;
             margin: 15px 0;
             border: 2px solid #e2e8f0;
@@ -148,9 +482,9 @@ func (a *SimpleAdmin) showLoginForm(w http.ResponseWriter) {
             border-color: #667eea;
         }
         button {
-            width: 100%;
+            width: 100%%;
             padding: 15px;
-            background: linear-gradient(135deg, #667eea 0%, #764ba2 100%);
+            background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%);
             color: white;
             border: none;
             border-radius: 10px;
@@ -161,14 +495,18 @@ func (a *SimpleAdmin) showLoginForm(w http.ResponseWriter) {
         }
         button:hover { transform: translateY(-2px); }
         .info { margin-top: 20px; color: #666; font-size: 14px; }
+        .error { background: #fee2e2; color: #991b1b; padding: 12px; border-radius: 8px; }
     </style>
 </head>
 <body>
     <div class="login-card">
         <div class="logo">🤖</div>
         <h1>DailyBot Admin</h1>
+        %s
         <form method="POST">
-            <input type="password" name="password" placeholder="Введите пароль администратора" required>
+            <input type="hidden" name="csrf" value="%s">
+            <input name="login" placeholder="Логин" autocomplete="username" required>
+            <input type="password" name="password" placeholder="Пароль" autocomplete="current-password" required>
             <button type="submit">Войти в панель</button>
         </form>
         <div class="info">
@@ -177,26 +515,23 @@ func (a *SimpleAdmin) showLoginForm(w http.ResponseWriter) {
         </div>
     </div>
 </body>
-</html>`
+</html>`, notice, token)
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	fmt.Fprint(w, html)
+	w.WriteHeader(status)
+	fmt.Fprint(w, page)
 }
 
-func (a *SimpleAdmin) showDashboard(w http.ResponseWriter) {
+func (a *SimpleAdmin) showDashboard(w http.ResponseWriter, sess session, account Account) {
 	a.mu.RLock()
 	stats := a.stats
 	a.mu.RUnlock()
 
-	// Считаем активных пользователей за 24 часа
-	activeCount := 0
-	now := time.Now()
-	for _, lastSeen := range stats.ActiveUsers {
-		if now.Sub(lastSeen) < 24*time.Hour {
-			activeCount++
-		}
-	}
+	// Карту ActiveUsers пополняет LogCommand, считаем по ней только под блокировкой
+	activeCount, totalUsers := a.userCounts()
 
 	uptime := time.Since(a.startTime)
+	queued, inFlight, capacity := a.queueStats()
+	health := a.health()
 
 	html := fmt.Sprintf(`<!DOCTYPE html>
 <html lang="ru">
@@ -208,7 +543,7 @@ func (a *SimpleAdmin) showDashboard(w http.ResponseWriter) {
         * { margin: 0; padding: 0; box-sizing: border-box; }
         body {
             font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, Arial, sans-serif;
-            background: linear-gradient(135deg, #667eea 0%, #764ba2 100%);
+            background: linear-gradient(135deg, #667eea 0%%, #764ba2 100%%);
             min-height: 100vh;
             padding: 20px;
         }
@@ -281,8 +616,19 @@ func (a *SimpleAdmin) showDashboard(w http.ResponseWriter) {
             box-shadow: 0 10px 30px rgba(0, 0, 0, 0.1);
             backdrop-filter: blur(10px);
         }
-        .status { color: #22c55e; font-weight: 600; }
-        .status::before { content: "🟢 "; }
+        .section { margin-bottom: 20px; }
+        .data-table { width: 100%%; border-collapse: collapse; }
+        .data-table th, .data-table td {
+            text-align: left;
+            padding: 8px 12px;
+            border-bottom: 1px solid #e2e8f0;
+        }
+        .data-table th { color: #666; font-weight: 500; }
+        .status { font-weight: 600; }
+        .status.ok { color: #22c55e; }
+        .status.ok::before { content: "🟢 "; }
+        .status.fail { color: #ef4444; }
+        .status.fail::before { content: "🔴 "; }
         .auto-refresh {
             background: #f0f9ff;
             border: 1px solid #0ea5e9;
@@ -318,11 +664,15 @@ func (a *SimpleAdmin) showDashboard(w http.ResponseWriter) {
         <div class="header">
             <div>
                 <h1>🤖 DailyBot Admin</h1>
-                <p>Панель управления Telegram-ботом</p>
+                <p>Панель управления Telegram-ботом · 👤 %s (%s)</p>
             </div>
             <div class="actions">
                 <button class="btn" onclick="location.reload()">🔄 Обновить</button>
-                <a href="?logout=1" class="btn">🚪 Выход</a>
+                %s
+                <form method="POST" action="/logout">
+                    <input type="hidden" name="csrf" value="%s">
+                    <button type="submit" class="btn">🚪 Выход</button>
+                </form>
             </div>
         </div>
         
@@ -387,6 +737,22 @@ func (a *SimpleAdmin) showDashboard(w http.ResponseWriter) {
                     <div class="stat-icon">⏱</div>
                 </div>
             </div>
+            
+            <div class="stat-card">
+                <div class="stat-header">
+                    <div>
+                        <div class="stat-number">%d</div>
+                        <div class="stat-label">📥 Обновлений в очереди</div>
+                    </div>
+                    <div class="stat-icon">📥</div>
+                </div>
+                <small style="color: #666;">в работе %d, лимит очереди %d</small>
+            </div>
+        </div>
+        
+        <div class="info-card section">
+            <h3 style="margin-bottom: 20px;">🗄 Кеш API</h3>
+            %s
         </div>
         
         <div class="info-card">
@@ -394,7 +760,7 @@ func (a *SimpleAdmin) showDashboard(w http.ResponseWriter) {
             <div style="display: grid; grid-template-columns: repeat(auto-fit, minmax(200px, 1fr)); gap: 20px;">
                 <div>
                     <strong>Статус:</strong><br>
-                    <span class="status">Онлайн и работает</span>
+                    %s
                 </div>
                 <div>
                     <strong>Запущен:</strong><br>
@@ -417,13 +783,19 @@ func (a *SimpleAdmin) showDashboard(w http.ResponseWriter) {
     </div>
 </body>
 </html>`,
+		html.EscapeString(account.Login), roleNames[account.Role],
+		dashboardLinks(account.Role),
+		sess.csrf,
 		stats.TotalMessages,
 		activeCount,
-		len(stats.ActiveUsers),
+		totalUsers,
 		stats.WeatherRequests,
 		stats.NewsRequests,
 		stats.ExchangeRequests,
 		formatDuration(uptime),
+		queued, inFlight, capacity,
+		renderCacheStats(api.CacheStats()),
+		a.renderHealth(health),
 		a.startTime.Format("02.01.2006 15:04:05"),
 	)
 
@@ -432,6 +804,13 @@ func (a *SimpleAdmin) showDashboard(w http.ResponseWriter) {
 }
 
 func (a *SimpleAdmin) handleStats(w http.ResponseWriter, r *http.Request) {
+	queued, inFlight, capacity := a.queueStats()
+
+	status := "ok"
+	if !a.health().ready() {
+		status = "degraded"
+	}
+
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 
@@ -446,10 +825,9 @@ func (a *SimpleAdmin) handleStats(w http.ResponseWriter, r *http.Request) {
 	uptime := time.Since(a.startTime)
 
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
 	fmt.Fprintf(w, `{
-		"status": "ok",
+		"status": "%s",
 		"totalMessages": %d,
 		"activeUsers": %d,
 		"totalUsers": %d,
@@ -458,10 +836,66 @@ func (a *SimpleAdmin) handleStats(w http.ResponseWriter, r *http.Request) {
 		"exchangeRequests": %d,
 		"uptimeSeconds": %.0f,
 		"uptimeFormatted": "%s",
-		"startTime": "%s"
-	}`, a.stats.TotalMessages, activeCount, len(a.stats.ActiveUsers),
+		"startTime": "%s",
+		"queue": {"queued": %d, "inFlight": %d, "capacity": %d},
+		"cache": %s
+	}`, status, a.stats.TotalMessages, activeCount, len(a.stats.ActiveUsers),
 		a.stats.WeatherRequests, a.stats.NewsRequests, a.stats.ExchangeRequests,
-		uptime.Seconds(), formatDuration(uptime), a.startTime.Format("2006-01-02 15:04:05"))
+		uptime.Seconds(), formatDuration(uptime), a.startTime.Format("2006-01-02 15:04:05"),
+		queued, inFlight, capacity,
+		cacheStatsJSON(api.CacheStats()))
+}
+
+// dashboardLinks - разделы панели, доступные роли
+func dashboardLinks(role Role) string {
+	links := ""
+	if role.allows(RoleOperator) {
+		links += `<a href="/limits" class="btn">🚦 Лимиты</a>
+                <a href="/broadcast" class="btn">📢 Рассылка</a>
+                <a href="/audit" class="btn">📝 Журнал</a>`
+	}
+	if role.allows(RoleOwner) {
+		links += `
+                <a href="/accounts" class="btn">👥 Учетные записи</a>
+                <a href="/tokens" class="btn">🔑 API</a>`
+	}
+	return links
+}
+
+func renderCacheStats(stats []api.CacheStat) string {
+	rows := ""
+	for _, s := range stats {
+		hitRate := 0.0
+		if total := s.Hits + s.Misses + s.Shared; total > 0 {
+			hitRate = float64(s.Hits) / float64(total) * 100
+		}
+		rows += fmt.Sprintf("<tr><td>%s</td><td>%d</td><td>%d</td><td>%d</td><td>%.0f%%</td><td>%d</td><td>%d</td></tr>",
+			html.EscapeString(s.Provider), s.Hits, s.Misses, s.Shared, hitRate, s.Stale, s.Entries)
+	}
+
+	return `<table class="data-table">
+                <tr><th>Провайдер</th><th>Попадания</th><th>Промахи</th><th>Ждали загрузку</th><th>Hit rate</th><th>Устаревшие</th><th>Записей</th></tr>
+                ` + rows + `
+            </table>`
+}
+
+func cacheStatsJSON(stats []api.CacheStat) string {
+	type cacheStat struct {
+		Provider string `json:"provider"`
+		Hits     int64  `json:"hits"`
+		Misses   int64  `json:"misses"`
+		Shared   int64  `json:"shared"`
+		Stale    int64  `json:"stale"`
+		Entries  int    `json:"entries"`
+	}
+
+	items := make([]cacheStat, 0, len(stats))
+	for _, s := range stats {
+		items = append(items, cacheStat{s.Provider, s.Hits, s.Misses, s.Shared, s.Stale, s.Entries})
+	}
+
+	data, _ := json.Marshal(items)
+	return string(data)
 }
 
 func formatDuration(d time.Duration) string {
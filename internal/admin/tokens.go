@@ -0,0 +1,276 @@
+package admin
+
+import (
+	"context"
+	"crypto/sha256"
+	"dailybot/internal/storage"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Токены отличаются от других строк по префиксу: так их проще найти в утечках
+const apiTokenPrefix = "dbt_"
+
+// apiToken - токен для /api/*. Дает только чтение, как у наблюдателя
+type apiToken struct {
+	hash      string // SHA-256 токена, сам токен показывается один раз при создании
+	name      string
+	createdBy string
+	createdAt time.Time
+}
+
+// tokenStore - токены в памяти, при заданной базе они сохраняются в api_tokens
+type tokenStore struct {
+	mu     sync.RWMutex
+	tokens map[string]apiToken
+}
+
+func newTokenStore() *tokenStore {
+	return &tokenStore{tokens: make(map[string]apiToken)}
+}
+
+func (s *tokenStore) put(token apiToken) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[token.hash] = token
+}
+
+func (s *tokenStore) delete(hash string) (apiToken, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.tokens[hash]
+	delete(s.tokens, hash)
+	return token, ok
+}
+
+// lookup ищет токен по значению из заголовка Authorization
+func (s *tokenStore) lookup(value string) (apiToken, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	token, ok := s.tokens[hashAPIToken(value)]
+	return token, ok
+}
+
+func (s *tokenStore) list() []apiToken {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tokens := make([]apiToken, 0, len(s.tokens))
+	for _, token := range s.tokens {
+		tokens = append(tokens, token)
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].createdAt.Before(tokens[j].createdAt) })
+	return tokens
+}
+
+// Токен случайный и длинный, поэтому медленный хеш вроде bcrypt не нужен
+func hashAPIToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// restoreTokens загружает токены API из базы
+func (a *SimpleAdmin) restoreTokens() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	saved, err := a.store.ListAPITokens(ctx)
+	if err != nil {
+		log.Printf("❌ Failed to restore API tokens: %v", err)
+		return
+	}
+	for _, t := range saved {
+		a.tokens.put(apiToken{hash: t.Hash, name: t.Name, createdBy: t.CreatedBy, createdAt: t.CreatedAt})
+	}
+}
+
+// apiAuth защищает /api/*: пускает с сессией панели или с токеном
+// в заголовке "Authorization: Bearer ...". CORS разрешен только для
+// сайтов из ADMIN_CORS_ORIGINS
+func (a *SimpleAdmin) apiAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" {
+			w.Header().Add("Vary", "Origin")
+			if slices.Contains(a.config.AdminCORSOrigins, origin) {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+		}
+
+		// Предварительный запрос браузера приходит без токена
+		if r.Method == http.MethodOptions {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization")
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if value, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			if _, found := a.tokens.lookup(strings.TrimSpace(value)); found {
+				next.ServeHTTP(w, r)
+				return
+			}
+		} else if _, _, ok := a.currentAccount(r); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("WWW-Authenticate", `Bearer realm="dailybot"`)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error": "unauthorized"}`)
+	})
+}
+
+// handleTokens - создание и отзыв токенов API, только для владельцев.
+// Новый токен показывается один раз прямо в ответе на POST
+func (a *SimpleAdmin) handleTokens(w http.ResponseWriter, r *http.Request) {
+	sess, account, ok := a.authorize(w, r, RoleOwner)
+	if !ok {
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		a.showTokens(w, sess, r.URL.Query().Get("error"), "")
+		return
+	}
+
+	if !checkCSRF(r, sess) {
+		http.Error(w, "Invalid CSRF token", http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	switch r.FormValue("action") {
+	case "create":
+		value, err := a.createToken(ctx, strings.TrimSpace(r.FormValue("name")), account)
+		if err != nil {
+			http.Redirect(w, r, "/tokens?error="+url.QueryEscape(err.Error()), http.StatusSeeOther)
+			return
+		}
+		a.showTokens(w, sess, "", value)
+
+	case "revoke":
+		target := "/tokens"
+		if err := a.revokeToken(ctx, r.FormValue("hash"), account); err != nil {
+			target += "?error=" + url.QueryEscape(err.Error())
+		}
+		http.Redirect(w, r, target, http.StatusSeeOther)
+
+	default:
+		http.Redirect(w, r, "/tokens?error="+url.QueryEscape("неизвестное действие"), http.StatusSeeOther)
+	}
+}
+
+func (a *SimpleAdmin) createToken(ctx context.Context, name string, actor Account) (string, error) {
+	if name == "" || utf8.RuneCountInString(name) > 64 {
+		return "", fmt.Errorf("название токена: от 1 до 64 символов")
+	}
+
+	random, err := randomToken()
+	if err != nil {
+		log.Printf("❌ Failed to generate API token: %v", err)
+		return "", fmt.Errorf("не удалось создать токен")
+	}
+	value := apiTokenPrefix + random
+
+	token := apiToken{hash: hashAPIToken(value), name: name, createdBy: actor.Login, createdAt: time.Now()}
+	a.tokens.put(token)
+	a.audit(actor.Login, auditTokenCreate, name)
+
+	if a.store != nil {
+		err := a.store.SaveAPIToken(ctx, storage.APIToken{
+			Hash:      token.hash,
+			Name:      token.name,
+			CreatedBy: token.createdBy,
+			CreatedAt: token.createdAt,
+		})
+		if err != nil {
+			// Токен, который пропадет после рестарта, лучше не выдавать
+			a.tokens.delete(token.hash)
+			log.Printf("❌ Failed to save API token: %v", err)
+			return "", fmt.Errorf("не удалось сохранить токен в базу")
+		}
+	}
+	return value, nil
+}
+
+func (a *SimpleAdmin) revokeToken(ctx context.Context, hash string, actor Account) error {
+	token, ok := a.tokens.delete(hash)
+	if !ok {
+		return fmt.Errorf("токен не найден")
+	}
+	a.audit(actor.Login, auditTokenRevoke, token.name)
+
+	if a.store != nil {
+		if err := a.store.DeleteAPIToken(ctx, hash); err != nil {
+			log.Printf("❌ Failed to delete API token: %v", err)
+			return fmt.Errorf("токен отозван до перезапуска: не удалось удалить из базы")
+		}
+	}
+	return nil
+}
+
+func (a *SimpleAdmin) showTokens(w http.ResponseWriter, sess session, errorText, created string) {
+	notice := ""
+	if created != "" {
+		notice = fmt.Sprintf(`<div class="success">Токен создан. Скопируйте его сейчас - больше он показан не будет:<br>
+            <code>%s</code></div>`, html.EscapeString(created))
+	}
+
+	rows := ""
+	for _, token := range a.tokens.list() {
+		rows += fmt.Sprintf(`<tr><td>%s</td><td>%s</td><td>%s</td><td>
+                <form method="POST" onsubmit="return confirm('Отозвать токен?')">
+                    <input type="hidden" name="csrf" value="%s">
+                    <input type="hidden" name="action" value="revoke">
+                    <input type="hidden" name="hash" value="%s">
+                    <button type="submit" class="danger">Отозвать</button>
+                </form></td></tr>`,
+			html.EscapeString(token.name), html.EscapeString(token.createdBy), token.createdAt.Format("02.01.2006 15:04"),
+			sess.csrf, token.hash)
+	}
+	if rows == "" {
+		rows = `<tr><td colspan="4" class="hint">Токенов нет</td></tr>`
+	}
+
+	origins := "не заданы - из браузера с других сайтов API недоступно"
+	if len(a.config.AdminCORSOrigins) > 0 {
+		origins = html.EscapeString(strings.Join(a.config.AdminCORSOrigins, ", "))
+	}
+
+	body := fmt.Sprintf(`%s<div class="info-card">
+            <h3>Токены API</h3>
+            <table class="data-table">
+                <tr><th>Название</th><th>Создал</th><th>Создан</th><th></th></tr>
+                %s
+            </table>
+            <form method="POST">
+                <input type="hidden" name="csrf" value="%s">
+                <input type="hidden" name="action" value="create">
+                <input name="name" placeholder="Для чего токен" maxlength="64" style="width: 260px;" required>
+                <button type="submit">Создать токен</button>
+            </form>
+        </div>
+
+        <div class="info-card">
+            <h3>Как пользоваться</h3>
+            <p><code>curl -H "Authorization: Bearer dbt_..." http://localhost:%s/api/stats</code></p>
+            <p class="hint">Токен дает доступ только на чтение к /api/*. Разрешенные сайты для CORS (ADMIN_CORS_ORIGINS): %s</p>
+        </div>`, notice, rows, sess.csrf, a.config.AdminPort, origins)
+
+	a.showPage(w, "🔑 Токены API", errorText, body)
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"dailybot/internal/i18n"
+	"dailybot/internal/metrics"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Общий HTTP-клиент для всех провайдеров: переиспользует соединения
+// и учитывает запросы в метриках по провайдеру и коду ответа
+var httpClient = &http.Client{Timeout: 10 * time.Second, Transport: metrics.UpstreamTransport{}}
+
+const (
+	// Сколько еще можно отдавать устаревшие данные, если провайдер недоступен
+	maxStaleAge = 24 * time.Hour
+	// При превышении лимита из кеша вычищаются самые старые записи
+	maxCacheEntries = 1000
+)
+
+// Budget - общий бюджет запросов к провайдерам. Провайдер - название кеша
+// без уточнения в скобках: "OpenWeather (прогноз)" расходует бюджет "OpenWeather"
+type Budget interface {
+	AllowProvider(provider string) (retryAfter time.Duration, ok bool)
+}
+
+var (
+	budgetMu sync.RWMutex
+	budget   Budget
+)
+
+// SetBudget включает ограничение запросов к провайдерам. nil - без ограничений
+func SetBudget(b Budget) {
+	budgetMu.Lock()
+	defer budgetMu.Unlock()
+	budget = b
+}
+
+// allowRequest проверяет бюджет перед запросом к провайдеру. Когда бюджет
+// исчерпан, кеш отдает устаревшие данные, если они есть
+func allowRequest(provider string) error {
+	budgetMu.RLock()
+	b := budget
+	budgetMu.RUnlock()
+
+	if b == nil {
+		return nil
+	}
+
+	quota, _, _ := strings.Cut(provider, " (")
+	if retryAfter, ok := b.AllowProvider(quota); !ok {
+		return i18n.NewError("ratelimit.provider", quota, i18n.Duration(retryAfter))
+	}
+	return nil
+}
+
+// CacheStat - счетчики кеша одного провайдера для админки. Shared - запросы,
+// которые дождались уже идущей загрузки того же ключа: это не попадание в кеш,
+// но и к провайдеру они не ходили
+type CacheStat struct {
+	Provider string
+	Hits     int64
+	Misses   int64
+	Shared   int64
+	Stale    int64
+	Entries  int
+}
+
+type statsReporter interface {
+	stat() CacheStat
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []statsReporter
+)
+
+// CacheStats возвращает статистику всех кешей в порядке регистрации
+func CacheStats() []CacheStat {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	stats := make([]CacheStat, 0, len(registry))
+	for _, c := range registry {
+		stats = append(stats, c.stat())
+	}
+	return stats
+}
+
+// cache хранит ответы одного провайдера с TTL. Одинаковые параллельные
+// запросы объединяются в один вызов к провайдеру, а при ошибке провайдера
+// отдаются устаревшие данные, если они есть.
+type cache[T any] struct {
+	provider string
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry[T]
+	calls   map[string]*cacheCall[T]
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	shared atomic.Int64
+	stale  atomic.Int64
+}
+
+type cacheEntry[T any] struct {
+	value     T
+	fetchedAt time.Time
+}
+
+type cacheCall[T any] struct {
+	done   chan struct{}
+	result cached[T]
+	err    error
+}
+
+// cached - значение из кеша и время, когда оно было получено от провайдера
+type cached[T any] struct {
+	value     T
+	fetchedAt time.Time
+	stale     bool
+}
+
+func newCache[T any](provider string, ttl time.Duration) *cache[T] {
+	c := &cache[T]{
+		provider: provider,
+		ttl:      ttl,
+		entries:  make(map[string]cacheEntry[T]),
+		calls:    make(map[string]*cacheCall[T]),
+	}
+
+	registryMu.Lock()
+	registry = append(registry, c)
+	registryMu.Unlock()
+
+	return c
+}
+
+func (c *cache[T]) get(key string, fetch func() (T, error)) (cached[T], error) {
+	c.mu.Lock()
+
+	if entry, ok := c.entries[key]; ok && time.Since(entry.fetchedAt) < c.ttl {
+		c.mu.Unlock()
+		c.hits.Add(1)
+		return cached[T]{value: entry.value, fetchedAt: entry.fetchedAt}, nil
+	}
+
+	// Такой же запрос уже выполняется - ждем его результат
+	if call, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+		c.shared.Add(1)
+		<-call.done
+		return call.result, call.err
+	}
+
+	call := &cacheCall[T]{done: make(chan struct{})}
+	c.calls[key] = call
+	c.mu.Unlock()
+
+	c.misses.Add(1)
+	var value T
+	err := allowRequest(c.provider)
+	if err == nil {
+		value, err = fetch()
+	}
+
+	c.mu.Lock()
+	now := time.Now()
+	if err == nil {
+		c.entries[key] = cacheEntry[T]{value: value, fetchedAt: now}
+		c.evictLocked(now)
+		call.result = cached[T]{value: value, fetchedAt: now}
+	} else if entry, ok := c.entries[key]; ok && now.Sub(entry.fetchedAt) < maxStaleAge {
+		c.stale.Add(1)
+		call.result = cached[T]{value: entry.value, fetchedAt: entry.fetchedAt, stale: true}
+	} else {
+		call.err = err
+	}
+	delete(c.calls, key)
+	c.mu.Unlock()
+
+	close(call.done)
+	return call.result, call.err
+}
+
+// evictLocked удаляет записи, которые уже нельзя отдать даже как устаревшие,
+// а если кеш все равно переполнен - самые старые
+func (c *cache[T]) evictLocked(now time.Time) {
+	if len(c.entries) <= maxCacheEntries {
+		return
+	}
+
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if now.Sub(entry.fetchedAt) >= maxStaleAge {
+			delete(c.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.fetchedAt.Before(oldest) {
+			oldestKey, oldest = key, entry.fetchedAt
+		}
+	}
+
+	if len(c.entries) > maxCacheEntries {
+		delete(c.entries, oldestKey)
+	}
+}
+
+func (c *cache[T]) stat() CacheStat {
+	c.mu.Lock()
+	entries := len(c.entries)
+	c.mu.Unlock()
+
+	return CacheStat{
+		Provider: c.provider,
+		Hits:     c.hits.Load(),
+		Misses:   c.misses.Load(),
+		Shared:   c.shared.Load(),
+		Stale:    c.stale.Load(),
+		Entries:  entries,
+	}
+}
+
+// normalizeKey приводит пользовательский ввод к ключу кеша:
+// "  Нижний   Новгород " и "нижний новгород" - один и тот же город
+func normalizeKey(parts ...string) string {
+	normalized := make([]string, len(parts))
+	for i, part := range parts {
+		normalized[i] = strings.ToLower(strings.Join(strings.Fields(part), " "))
+	}
+	return strings.Join(normalized, "|")
+}
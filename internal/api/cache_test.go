@@ -0,0 +1,165 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testTimeout = 5 * time.Second
+
+func TestCacheCollapsesConcurrentLoads(t *testing.T) {
+	c := newCache[int]("test", time.Minute)
+
+	var loads atomic.Int64
+	release := make(chan struct{})
+	fetch := func() (int, error) {
+		loads.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	const callers = 10
+	results := make(chan int, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := c.get("key", fetch)
+			if err != nil {
+				t.Errorf("get: %v", err)
+				return
+			}
+			results <- result.value
+		}()
+	}
+
+	// Отпускаем загрузку, когда все остальные ждут ее результата
+	deadline := time.Now().Add(testTimeout)
+	for c.shared.Load() != callers-1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d callers are waiting for the load, want %d", c.shared.Load(), callers-1)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	close(results)
+
+	if got := loads.Load(); got != 1 {
+		t.Fatalf("%d loads for concurrent calls, want 1", got)
+	}
+	for value := range results {
+		if value != 42 {
+			t.Fatalf("got %d, want 42", value)
+		}
+	}
+}
+
+func TestCacheReturnsFreshValueWithoutLoad(t *testing.T) {
+	c := newCache[int]("test", time.Minute)
+
+	if _, err := c.get("key", func() (int, error) { return 1, nil }); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+
+	result, err := c.get("key", func() (int, error) {
+		t.Fatal("fresh value is loaded again")
+		return 0, nil
+	})
+	if err != nil || result.value != 1 || result.stale {
+		t.Fatalf("get = %+v, %v; want fresh 1", result, err)
+	}
+}
+
+func TestCacheReloadsAfterTTL(t *testing.T) {
+	c := newCache[int]("test", time.Minute)
+	c.get("key", func() (int, error) { return 1, nil })
+	expire(c, "key", 2*time.Minute)
+
+	result, err := c.get("key", func() (int, error) { return 2, nil })
+	if err != nil || result.value != 2 || result.stale {
+		t.Fatalf("get = %+v, %v; want fresh 2", result, err)
+	}
+}
+
+func TestCacheServesStaleValueOnError(t *testing.T) {
+	c := newCache[int]("test", time.Minute)
+	c.get("key", func() (int, error) { return 1, nil })
+	expire(c, "key", time.Hour)
+
+	failed := errors.New("provider is down")
+	result, err := c.get("key", func() (int, error) { return 0, failed })
+	if err != nil {
+		t.Fatalf("get: %v, want stale value", err)
+	}
+	if result.value != 1 || !result.stale {
+		t.Fatalf("get = %+v, want stale 1", result)
+	}
+	if got := c.stale.Load(); got != 1 {
+		t.Fatalf("stale counter = %d, want 1", got)
+	}
+
+	// Слишком старое значение уже не отдается
+	expire(c, "key", maxStaleAge)
+	if _, err := c.get("key", func() (int, error) { return 0, failed }); !errors.Is(err, failed) {
+		t.Fatalf("get = %v, want loader error", err)
+	}
+}
+
+func TestCacheEvictsOutdatedEntries(t *testing.T) {
+	c := newCache[int]("test", time.Minute)
+	fill(c, maxCacheEntries-1)
+	c.entries["outdated"] = cacheEntry[int]{fetchedAt: time.Now().Add(-maxStaleAge)}
+
+	c.get("new", func() (int, error) { return 1, nil })
+
+	if _, ok := c.entries["outdated"]; ok {
+		t.Fatal("entry older than maxStaleAge is kept")
+	}
+	if _, ok := c.entries["0"]; !ok {
+		t.Fatal("oldest entry is evicted although outdated one was enough")
+	}
+	if got := len(c.entries); got != maxCacheEntries {
+		t.Fatalf("%d entries, want %d", got, maxCacheEntries)
+	}
+}
+
+func TestCacheEvictsOldestOverCapacity(t *testing.T) {
+	c := newCache[int]("test", time.Minute)
+	fill(c, maxCacheEntries)
+
+	c.get("new", func() (int, error) { return 1, nil })
+
+	if _, ok := c.entries["0"]; ok {
+		t.Fatal("oldest entry is kept over capacity")
+	}
+	if _, ok := c.entries["new"]; !ok {
+		t.Fatal("new entry is evicted")
+	}
+	if got := len(c.entries); got != maxCacheEntries {
+		t.Fatalf("%d entries, want %d", got, maxCacheEntries)
+	}
+}
+
+// fill добавляет n записей "0", "1", ...: чем меньше номер, тем запись старше
+func fill[T any](c *cache[T], n int) {
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < n; i++ {
+		c.entries[fmt.Sprint(i)] = cacheEntry[T]{fetchedAt: start.Add(time.Duration(i) * time.Second)}
+	}
+}
+
+// expire делает запись старше на age
+func expire[T any](c *cache[T], key string, age time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := c.entries[key]
+	entry.fetchedAt = entry.fetchedAt.Add(-age)
+	c.entries[key] = entry
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"dailybot/internal/i18n"
+	"html"
+	"math"
+	"strings"
+	"time"
+)
+
+// Conversion - результат пересчета суммы. FromRate и ToRate - стоимость
+// одной единицы валют в рублях, Date - дата курсов ЦБ (RFC3339)
+type Conversion struct {
+	Amount   float64
+	From     string
+	To       string
+	Rate     float64
+	FromRate float64
+	ToRate   float64
+	Date     string
+
+	FetchedAt time.Time
+	Stale     bool
+}
+
+// ConvertCurrency пересчитывает сумму из одной валюты в другую через рубль.
+// ЦБ публикует курсы за Nominal единиц (JPY - за 100, KZT - за 100),
+// поэтому считаем курс одной единицы как Value / Nominal.
+func ConvertCurrency(amount float64, from, to string) (Conversion, error) {
+	from = strings.ToUpper(strings.TrimSpace(from))
+	to = strings.ToUpper(strings.TrimSpace(to))
+
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return Conversion{}, i18n.NewError("convert.err.not_number")
+	}
+	if amount <= 0 {
+		return Conversion{}, i18n.NewError("convert.err.amount")
+	}
+
+	result, err := getRates(cbrDailyURL)
+	if err != nil {
+		return Conversion{}, err
+	}
+	data := result.value
+
+	fromRate, err := rubPerUnit(data, from)
+	if err != nil {
+		return Conversion{}, err
+	}
+
+	toRate, err := rubPerUnit(data, to)
+	if err != nil {
+		return Conversion{}, err
+	}
+
+	return Conversion{
+		Amount:    amount,
+		From:      from,
+		To:        to,
+		Rate:      fromRate / toRate,
+		FromRate:  fromRate,
+		ToRate:    toRate,
+		Date:      data.Date,
+		FetchedAt: result.fetchedAt,
+		Stale:     result.stale,
+	}, nil
+}
+
+// rubPerUnit возвращает стоимость одной единицы валюты в рублях
+func rubPerUnit(data *ExchangeResponse, code string) (float64, error) {
+	if code == "RUB" {
+		return 1, nil
+	}
+
+	currency, exists := data.Valute[code]
+	if !exists || currency.Nominal <= 0 {
+		return 0, i18n.NewError("exchange.err.currency_not_found", html.EscapeString(code))
+	}
+
+	return currency.Value / float64(currency.Nominal), nil
+}
@@ -1,9 +1,8 @@
 package api
 
 import (
+	"dailybot/internal/i18n"
 	"encoding/json"
-	"fmt"
-	"net/http"
 	"strings"
 	"time"
 )
@@ -26,79 +25,103 @@ type Currency struct {
 	Previous float64 `json:"Previous"`
 }
 
-func GetExchangeRate(currencyCode string) (string, error) {
+const cbrDailyURL = "https://www.cbr-xml-daily.ru/daily_json.js"
+
+var (
+	ratesCache   = newCache[*ExchangeResponse]("ЦБ РФ", time.Hour)
+	archiveCache = newCache[*ExchangeResponse]("ЦБ РФ (архив)", 24*time.Hour)
+)
+
+var errRatesNotPublished = i18n.NewError("exchange.err.not_published")
+
+// Популярные валюты, которые предлагаются вместо неизвестной
+var popularCurrencies = []string{"USD", "EUR", "CNY", "GBP", "JPY", "CHF", "TRY", "KZT", "BYN"}
+
+// RateQuote - текущий курс валюты ЦБ
+type RateQuote struct {
+	Currency  Currency
+	FetchedAt time.Time
+	Stale     bool // ЦБ недоступен, курс из кеша
+}
+
+// UnknownCurrencyError - ЦБ не публикует курс такой валюты.
+// Available - популярные валюты, курс которых есть
+type UnknownCurrencyError struct {
+	Code      string
+	Available []Currency
+}
+
+func (e *UnknownCurrencyError) Error() string {
+	return i18n.T(i18n.Default, "exchange.err.currency_not_found", e.Code)
+}
+
+func GetExchangeRate(currencyCode string) (RateQuote, error) {
 	currencyCode = strings.ToUpper(strings.TrimSpace(currencyCode))
 
 	if currencyCode == "" {
-		return "", fmt.Errorf("укажите код валюты")
+		return RateQuote{}, i18n.NewError("exchange.err.no_code")
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get("https://www.cbr-xml-daily.ru/daily_json.js")
+	result, err := getRates(cbrDailyURL)
 	if err != nil {
-		return "", fmt.Errorf("ошибка соединения с сервисом курсов валют")
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("ошибка сервиса курсов валют (код %d)", resp.StatusCode)
-	}
-
-	var data ExchangeResponse
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return "", fmt.Errorf("ошибка обработки данных курсов валют")
+		return RateQuote{}, err
 	}
 
-	currency, exists := data.Valute[currencyCode]
+	currency, exists := result.value.Valute[currencyCode]
 	if !exists {
-		return getAvailableCurrencies(data.Valute), nil
+		unknown := &UnknownCurrencyError{Code: currencyCode}
+		for _, code := range popularCurrencies {
+			if currency, exists := result.value.Valute[code]; exists {
+				unknown.Available = append(unknown.Available, currency)
+			}
+		}
+		return RateQuote{}, unknown
 	}
 
-	return formatExchangeRate(currency), nil
+	return RateQuote{Currency: currency, FetchedAt: result.fetchedAt, Stale: result.stale}, nil
 }
 
-func formatExchangeRate(currency Currency) string {
-	change := currency.Value - currency.Previous
-	changeText := "без изменений"
-
-	if change > 0 {
-		changeText = fmt.Sprintf("рост на %.4f ₽", change)
-	} else if change < 0 {
-		changeText = fmt.Sprintf("падение на %.4f ₽", -change)
+// FetchExchangeRates возвращает текущий снимок курсов ЦБ целиком
+func FetchExchangeRates() (*ExchangeResponse, error) {
+	result, err := getRates(cbrDailyURL)
+	if err != nil {
+		return nil, err
 	}
+	return result.value, nil
+}
 
-	nominalText := ""
-	if currency.Nominal > 1 {
-		nominalText = fmt.Sprintf(" (за %d %s)", currency.Nominal, currency.CharCode)
+// getRates достает снимок курсов из кеша. Архивные снимки не меняются,
+// поэтому хранятся дольше текущего
+func getRates(url string) (cached[*ExchangeResponse], error) {
+	c := ratesCache
+	if url != cbrDailyURL {
+		c = archiveCache
 	}
-
-	return fmt.Sprintf(`<b>Курс валюты %s - %s</b>
-
-<b>Текущий курс:</b> %.4f ₽%s
-<b>Предыдущий курс:</b> %.4f ₽
-<b>Изменение:</b> %s
-
-<i>Данные Центрального банка РФ</i>`,
-		currency.CharCode,
-		currency.Name,
-		currency.Value,
-		nominalText,
-		currency.Previous,
-		changeText)
+	return c.get(url, func() (*ExchangeResponse, error) {
+		return fetchRates(url)
+	})
 }
 
-func getAvailableCurrencies(valute map[string]Currency) string {
-	result := fmt.Sprintf("<b>Валюта не найдена</b>\n\n<b>Доступные валюты:</b>\n")
+func fetchRates(url string) (*ExchangeResponse, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, i18n.NewError("exchange.err.connection")
+	}
+	defer resp.Body.Close()
+
+	// Архив отвечает 404 за дни, когда ЦБ курсы не устанавливал
+	if resp.StatusCode == 404 {
+		return nil, errRatesNotPublished
+	}
 
-	// Показываем популярные валюты
-	popular := []string{"USD", "EUR", "CNY", "GBP", "JPY", "CHF", "TRY", "KZT", "BYN"}
+	if resp.StatusCode != 200 {
+		return nil, i18n.NewError("exchange.err.status", resp.StatusCode)
+	}
 
-	for _, code := range popular {
-		if currency, exists := valute[code]; exists {
-			result += fmt.Sprintf("• %s - %s\n", code, currency.Name)
-		}
+	var data ExchangeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, i18n.NewError("exchange.err.decode")
 	}
 
-	result += "\n<i>Пример: /exchange USD</i>"
-	return result
+	return &data, nil
 }
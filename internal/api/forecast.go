@@ -0,0 +1,91 @@
+package api
+
+import (
+	"dailybot/internal/i18n"
+	"time"
+)
+
+const MaxForecastDays = 5
+
+// DayForecast - прогноз на один день
+type DayForecast struct {
+	Date        time.Time
+	TempMin     float64
+	TempMax     float64
+	PrecipMax   float64
+	Description string
+}
+
+// Forecast возвращает прогноз на days дней начиная с сегодняшнего
+func (s *WeatherService) Forecast(place Place, days int, lang i18n.Lang, units Units) (Forecast, error) {
+	if days < 1 || days > MaxForecastDays {
+		return Forecast{}, i18n.NewError("forecast.err.days", MaxForecastDays)
+	}
+
+	if s.demo {
+		return demoForecast(place, 0, days), nil
+	}
+
+	forecast, err := s.forecast(place, lang, units)
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	forecast.Days = forecast.Days[:min(days, len(forecast.Days))]
+	return forecast, nil
+}
+
+// TomorrowForecast возвращает прогноз только на завтрашний день
+func (s *WeatherService) TomorrowForecast(place Place, lang i18n.Lang, units Units) (Forecast, error) {
+	if s.demo {
+		forecast := demoForecast(place, 1, 1)
+		forecast.Tomorrow = true
+		return forecast, nil
+	}
+
+	forecast, err := s.forecast(place, lang, units)
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	if len(forecast.Days) < 2 {
+		return Forecast{}, i18n.NewError("forecast.err.tomorrow")
+	}
+
+	forecast.Days = forecast.Days[1:2]
+	forecast.Tomorrow = true
+	return forecast, nil
+}
+
+func (s *WeatherService) forecast(place Place, lang i18n.Lang, units Units) (Forecast, error) {
+	units = ParseUnits(string(units))
+	forecast, err := firstAvailable(s.providers, func(p WeatherProvider) (Forecast, bool, error) {
+		f, err := p.Forecast(place, lang, units)
+		if err == nil && len(f.Days) == 0 {
+			err = i18n.NewError("forecast.err.response")
+		}
+		return f, f.Stale, err
+	})
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	forecast.Units = units
+	return forecast, nil
+}
+
+// demoForecast - выдуманный прогноз на days дней, начиная через from дней от сегодня
+func demoForecast(place Place, from, days int) Forecast {
+	forecast := Forecast{Place: place.title(), Units: Metric, Demo: true}
+
+	today := time.Now()
+	for i := from; i < from+days; i++ {
+		forecast.Days = append(forecast.Days, DayForecast{
+			Date:      today.AddDate(0, 0, i),
+			TempMin:   float64(15 + i),
+			TempMax:   float64(22 + i),
+			PrecipMax: 0.2,
+		})
+	}
+	return forecast
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Place - населенный пункт, найденный геокодером
+type Place struct {
+	Name    string
+	State   string // регион, может быть пустым
+	Country string // код страны ISO 3166
+	Lat     float64
+	Lon     float64
+}
+
+// Label - название с регионом и страной: "Кировск, Мурманская область, RU"
+func (p Place) Label() string {
+	parts := []string{p.Name}
+	if p.State != "" {
+		parts = append(parts, p.State)
+	}
+	if p.Country != "" {
+		parts = append(parts, p.Country)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// title - название места для заголовка ответа: "Кировск (Мурманская область)".
+// Для места без названия - координаты
+func (p Place) title() string {
+	if p.Name == "" {
+		return FormatCoordinates(p.Lat, p.Lon)
+	}
+	if p.State != "" {
+		return p.Name + " (" + p.State + ")"
+	}
+	return p.Name
+}
+
+// FormatCoordinates - координаты в виде "55.7558, 37.6173"
+func FormatCoordinates(lat, lon float64) string {
+	return strconv.FormatFloat(lat, 'f', 4, 64) + ", " + strconv.FormatFloat(lon, 'f', 4, 64)
+}
+
+// coordinatesKey - ключ кеша для запроса по координатам. Координаты округлены
+// до сотых (около километра), чтобы соседние точки делили запись
+func coordinatesKey(place Place, parts ...string) string {
+	lat, lon := roundedCoordinates(place)
+	return normalizeKey(append([]string{lat, lon}, parts...)...)
+}
+
+func roundedCoordinates(place Place) (string, string) {
+	return strconv.FormatFloat(place.Lat, 'f', 2, 64), strconv.FormatFloat(place.Lon, 'f', 2, 64)
+}
+
+const maxGeocodingResults = 5
+
+// uniquePlaces убирает повторы: геокодеры иногда возвращают одно и то же
+// место несколько раз с немного разными координатами
+func uniquePlaces(found []Place) []Place {
+	places := make([]Place, 0, len(found))
+	seen := make(map[string]bool)
+	for _, place := range found {
+		if key := normalizeKey(place.Label()); !seen[key] {
+			seen[key] = true
+			places = append(places, place)
+		}
+	}
+	return places
+}
+
+// Крупные города для подсказок при опечатке. Пополняется названиями,
+// которые возвращал геокодер
+var (
+	knownCitiesMu sync.RWMutex
+	knownCities   = make(map[string]string)
+)
+
+func init() {
+	for _, city := range []string{
+		"Москва", "Санкт-Петербург", "Новосибирск", "Екатеринбург", "Казань",
+		"Нижний Новгород", "Челябинск", "Самара", "Омск", "Ростов-на-Дону",
+		"Уфа", "Красноярск", "Воронеж", "Пермь", "Волгоград", "Краснодар",
+		"Саратов", "Тюмень", "Тольятти", "Ижевск", "Барнаул", "Иркутск",
+		"Хабаровск", "Владивосток", "Ярославль", "Томск", "Оренбург",
+		"Кемерово", "Рязань", "Астрахань", "Пенза", "Калининград", "Мурманск",
+		"Архангельск", "Сочи", "Севастополь", "Симферополь", "Минск", "Алматы",
+		"Астана", "Ташкент", "Бишкек", "Ереван", "Тбилиси", "Баку",
+		"Moscow", "London", "Paris", "Berlin", "Madrid", "Rome", "Vienna",
+		"Prague", "Warsaw", "Istanbul", "Dubai", "New York", "Los Angeles",
+		"Chicago", "Toronto", "Tokyo", "Beijing", "Shanghai", "Seoul",
+		"Bangkok", "Singapore", "Sydney",
+	} {
+		rememberCity(city)
+	}
+}
+
+func rememberCity(name string) {
+	key := normalizeKey(name)
+	if key == "" {
+		return
+	}
+
+	knownCitiesMu.Lock()
+	defer knownCitiesMu.Unlock()
+
+	if _, exists := knownCities[key]; !exists && len(knownCities) < maxCacheEntries {
+		knownCities[key] = name
+	}
+}
+
+// SuggestCities подбирает известные города, похожие на query: расстояние
+// Левенштейна не больше трети длины запроса. Самые близкие - первыми
+func SuggestCities(query string, limit int) []string {
+	key := normalizeKey(query)
+	maxDistance := utf8.RuneCountInString(key) / 3
+	if maxDistance == 0 {
+		return nil
+	}
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+	var candidates []candidate
+
+	knownCitiesMu.RLock()
+	for cityKey, name := range knownCities {
+		if d := levenshtein(key, cityKey); d > 0 && d <= maxDistance {
+			candidates = append(candidates, candidate{name, d})
+		}
+	}
+	knownCitiesMu.RUnlock()
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].name < candidates[j].name
+	})
+
+	var suggestions []string
+	for i := 0; i < len(candidates) && i < limit; i++ {
+		suggestions = append(suggestions, candidates[i].name)
+	}
+	return suggestions
+}
+
+// levenshtein считает расстояние редактирования по символам, а не байтам
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
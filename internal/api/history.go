@@ -0,0 +1,143 @@
+package api
+
+import (
+	"dailybot/internal/i18n"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+)
+
+const (
+	MaxHistoryDays = 31
+
+	// За сколько дней назад ищем ближайший опубликованный курс:
+	// длинные новогодние праздники длятся до 10 дней
+	maxArchiveLookback = 12
+)
+
+// Курсы ЦБ устанавливаются по московскому времени
+var cbrLocation = time.FixedZone("MSK", 3*60*60)
+
+type RatePoint struct {
+	Date  time.Time
+	Value float64 // курс за одну единицу валюты
+}
+
+// HistoricalRate - курс, действовавший на дату Requested.
+// Published - дата публикации курса ЦБ (RFC3339), может быть раньше Requested
+type HistoricalRate struct {
+	Currency  Currency
+	Requested time.Time
+	Published string
+}
+
+// RateHistory - курсы за последние Days дней в хронологическом порядке
+type RateHistory struct {
+	Code   string
+	Days   int
+	Points []RatePoint
+}
+
+// GetExchangeRateOn возвращает курс, действовавший на указанную дату.
+// В выходные и праздники ЦБ курс не устанавливает - берем последний
+// опубликованный до этой даты.
+func GetExchangeRateOn(currencyCode string, date time.Time) (HistoricalRate, error) {
+	currencyCode = strings.ToUpper(strings.TrimSpace(currencyCode))
+
+	date = truncateToDay(date.In(cbrLocation))
+	if date.After(time.Now().In(cbrLocation)) {
+		return HistoricalRate{}, i18n.NewError("history.err.future")
+	}
+
+	for i := 0; i < maxArchiveLookback; i++ {
+		result, err := getRates(archiveURL(date.AddDate(0, 0, -i)))
+		if errors.Is(err, errRatesNotPublished) {
+			continue
+		}
+		if err != nil {
+			return HistoricalRate{}, err
+		}
+		data := result.value
+
+		currency, exists := data.Valute[currencyCode]
+		if !exists {
+			return HistoricalRate{}, i18n.NewError("history.err.not_in_data", currencyCode, date.Format("02.01.2006"))
+		}
+
+		return HistoricalRate{Currency: currency, Requested: date, Published: data.Date}, nil
+	}
+
+	return HistoricalRate{}, i18n.NewError("history.err.lookback", maxArchiveLookback, date.Format("02.01.2006"))
+}
+
+// GetExchangeRateHistory проходит по архиву ЦБ назад через PreviousURL
+// и считает минимум, максимум и тренд за последние days дней.
+// PreviousURL всегда указывает на предыдущий опубликованный курс,
+// поэтому выходные и праздники пропускаются сами собой.
+func GetExchangeRateHistory(currencyCode string, days int) (RateHistory, error) {
+	currencyCode = strings.ToUpper(strings.TrimSpace(currencyCode))
+
+	if days < 1 || days > MaxHistoryDays {
+		return RateHistory{}, i18n.NewError("history.err.period", MaxHistoryDays)
+	}
+
+	since := truncateToDay(time.Now().In(cbrLocation)).AddDate(0, 0, -days)
+
+	var points []RatePoint
+	url := cbrDailyURL
+
+	for i := 0; i <= days+maxArchiveLookback && url != ""; i++ {
+		result, err := getRates(url)
+		if err != nil {
+			return RateHistory{}, err
+		}
+		data := result.value
+
+		currency, exists := data.Valute[currencyCode]
+		if !exists || currency.Nominal <= 0 {
+			if len(points) == 0 {
+				return RateHistory{}, i18n.NewError("exchange.err.currency_not_found", html.EscapeString(currencyCode))
+			}
+			break
+		}
+
+		date, err := time.Parse(time.RFC3339, data.Date)
+		if err != nil {
+			return RateHistory{}, i18n.NewError("exchange.err.decode")
+		}
+
+		points = append(points, RatePoint{Date: date, Value: currency.Value / float64(currency.Nominal)})
+
+		// Курс, действовавший на начало периода, тоже нужен - от него считаем тренд
+		if !date.After(since) {
+			break
+		}
+
+		url = normalizeArchiveURL(data.PreviousURL)
+	}
+
+	// Разворачиваем в хронологический порядок
+	for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+		points[i], points[j] = points[j], points[i]
+	}
+
+	return RateHistory{Code: currencyCode, Days: days, Points: points}, nil
+}
+
+func archiveURL(date time.Time) string {
+	return fmt.Sprintf("https://www.cbr-xml-daily.ru/archive/%s/daily_json.js", date.Format("2006/01/02"))
+}
+
+// normalizeArchiveURL дописывает схему: ЦБ отдает ссылки вида //www.cbr-xml-daily.ru/...
+func normalizeArchiveURL(url string) string {
+	if strings.HasPrefix(url, "//") {
+		return "https:" + url
+	}
+	return url
+}
+
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
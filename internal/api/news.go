@@ -1,20 +1,22 @@
 package api
 
 import (
+	"dailybot/internal/i18n"
 	"encoding/json"
 	"fmt"
 	"log"
-	"net/http"
+	"net/url"
 	"time"
 )
 
 type NewsResponse struct {
-	Status       string    `json:"status"`
-	TotalResults int       `json:"totalResults"`
-	Articles     []Article `json:"articles"`
+	Status       string           `json:"status"`
+	TotalResults int              `json:"totalResults"`
+	Articles     []NewsAPIArticle `json:"articles"`
 }
 
-type Article struct {
+// NewsAPIArticle - статья в формате NewsAPI
+type NewsAPIArticle struct {
 	Source struct {
 		ID   *string `json:"id"`
 		Name string  `json:"name"`
@@ -28,164 +30,229 @@ type Article struct {
 	Content     *string `json:"content"`
 }
 
-func GetNews(apiKey string) (string, error) {
+// Article - новость без привязки к формату NewsAPI
+type Article struct {
+	Title       string
+	Description string // может быть пустым
+	Source      string // может быть пустым
+	URL         string
+	PublishedAt time.Time
+}
+
+// NewsPage - страница новостей. Demo - заглушка без статей, когда нет ключа
+// NewsAPI или новостей не нашлось
+type NewsPage struct {
+	Articles []Article
+	Page     int
+	HasMore  bool
+
+	FetchedAt time.Time
+	Stale     bool
+	Demo      bool
+}
+
+const (
+	NewsPageSize = 5
+	// Бесплатный тариф NewsAPI отдает не больше 100 результатов
+	MaxNewsPages = 100 / NewsPageSize
+)
+
+// newsResult - одна страница новостей и общее число найденных статей
+type newsResult struct {
+	Articles []Article
+	Total    int
+}
+
+var newsCache = newCache[newsResult]("NewsAPI", 15*time.Minute)
+
+// newsSource - откуда брать новости для языка: страна для топа
+// и запрос для поиска, если в топе пусто
+type newsSource struct {
+	country  string
+	language string
+	query    string
+}
+
+var newsSources = map[i18n.Lang]newsSource{
+	i18n.RU: {country: "ru", language: "ru", query: "технологии OR политика OR экономика"},
+	i18n.EN: {country: "us", language: "en", query: "technology OR politics OR economy"},
+}
+
+func GetNews(apiKey string, lang i18n.Lang) (NewsPage, error) {
+	return GetNewsPage(apiKey, 1, lang)
+}
+
+// GetNewsPage возвращает страницу новостей и признак того, что есть следующая
+func GetNewsPage(apiKey string, page int, lang i18n.Lang) (NewsPage, error) {
+	if page < 1 || page > MaxNewsPages {
+		return NewsPage{}, i18n.NewError("news.err.page", MaxNewsPages)
+	}
+
 	if apiKey == "" {
-		return getNewsStub(), nil
+		if page > 1 {
+			return NewsPage{}, i18n.NewError("news.err.demo_page")
+		}
+		return NewsPage{Page: page, Demo: true}, nil
 	}
 
-	// Сначала пробуем российские новости
-	newsText, err := fetchNews(apiKey, "ru")
-	if err != nil {
-		return "", err
+	source, ok := newsSources[lang]
+	if !ok {
+		source = newsSources[i18n.Default]
 	}
 
-	// Если российских новостей нет, пробуем общие новости
-	if newsText == "" {
-		log.Println("No Russian news found, trying general news...")
-		newsText, err = fetchNewsGeneral(apiKey)
-		if err != nil {
-			return "", err
-		}
+	result, err := newsCache.get(fmt.Sprintf("top|%s|%d", source.country, page), func() (newsResult, error) {
+		return loadNews(apiKey, source, page)
+	})
+	if err != nil {
+		return NewsPage{}, err
 	}
 
 	// Если и общих новостей нет, возвращаем заглушку
-	if newsText == "" {
+	if len(result.value.Articles) == 0 {
+		if page > 1 {
+			return NewsPage{}, i18n.NewError("news.err.no_more")
+		}
 		log.Println("No news found, returning stub")
-		return getNewsStub(), nil
+		return NewsPage{Page: page, Demo: true}, nil
+	}
+
+	articles := result.value.Articles
+	if len(articles) > NewsPageSize {
+		articles = articles[:NewsPageSize]
 	}
 
-	return newsText, nil
+	return NewsPage{
+		Articles:  articles,
+		Page:      page,
+		HasMore:   result.value.Total > page*NewsPageSize && page < MaxNewsPages,
+		FetchedAt: result.fetchedAt,
+		Stale:     result.stale,
+	}, nil
 }
 
-func fetchNews(apiKey, country string) (string, error) {
-	url := fmt.Sprintf("https://newsapi.org/v2/top-headlines?country=%s&pageSize=5&apiKey=%s", country, apiKey)
+func loadNews(apiKey string, source newsSource, page int) (newsResult, error) {
+	// Сначала пробуем главные новости страны
+	news, err := fetchNews(apiKey, source.country, page)
+	if err != nil {
+		return newsResult{}, err
+	}
 
-	log.Printf("Fetching news from: %s", url)
+	// Если новостей страны нет, пробуем общие новости
+	if len(news.Articles) == 0 {
+		log.Printf("No top headlines for %q, trying general news...", source.country)
+		news, err = fetchNewsGeneral(apiKey, source, page)
+		if err != nil {
+			return newsResult{}, err
+		}
+	}
+
+	return news, nil
+}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(url)
+func fetchNews(apiKey, country string, page int) (newsResult, error) {
+	requestURL := fmt.Sprintf("https://newsapi.org/v2/top-headlines?country=%s&pageSize=%d&page=%d&apiKey=%s", country, NewsPageSize, page, apiKey)
+
+	log.Printf("Fetching news from: %s", redactURL(requestURL))
+
+	resp, err := httpClient.Get(requestURL)
 	if err != nil {
-		return "", fmt.Errorf("ошибка соединения с сервисом новостей")
+		return newsResult{}, i18n.NewError("news.err.connection")
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == 401 {
-		return "", fmt.Errorf("неверный API ключ NewsAPI")
+		return newsResult{}, i18n.NewError("news.err.bad_key")
 	}
 
 	if resp.StatusCode == 429 {
-		return "", fmt.Errorf("превышен лимит запросов к API новостей")
+		return newsResult{}, i18n.NewError("news.err.rate_limit")
 	}
 
 	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("ошибка сервиса новостей (код %d)", resp.StatusCode)
+		return newsResult{}, i18n.NewError("news.err.status", resp.StatusCode)
 	}
 
 	var news NewsResponse
 	if err := json.NewDecoder(resp.Body).Decode(&news); err != nil {
-		return "", fmt.Errorf("ошибка обработки данных новостей")
+		return newsResult{}, i18n.NewError("news.err.decode")
 	}
 
 	log.Printf("News API response: status=%s, totalResults=%d, articles=%d",
 		news.Status, news.TotalResults, len(news.Articles))
 
 	if news.Status != "ok" {
-		return "", fmt.Errorf("ошибка получения новостей")
-	}
-
-	if len(news.Articles) == 0 {
-		return "", nil // пустая строка означает "нет новостей"
+		return newsResult{}, i18n.NewError("news.err.response")
 	}
 
-	return formatNews(news.Articles), nil
+	return newsResult{Articles: convertArticles(news.Articles), Total: news.TotalResults}, nil
 }
 
-func fetchNewsGeneral(apiKey string) (string, error) {
+func fetchNewsGeneral(apiKey string, source newsSource, page int) (newsResult, error) {
+	// Кеш расходует бюджет на первый запрос промаха, этот - второй
+	if err := allowRequest(newsCache.provider); err != nil {
+		return newsResult{}, err
+	}
+
 	// Пробуем общие новости по ключевым словам
-	url := fmt.Sprintf("https://newsapi.org/v2/everything?q=технологии OR политика OR экономика&language=ru&sortBy=publishedAt&pageSize=5&apiKey=%s", apiKey)
+	requestURL := fmt.Sprintf("https://newsapi.org/v2/everything?q=%s&language=%s&sortBy=publishedAt&pageSize=%d&page=%d&apiKey=%s",
+		url.QueryEscape(source.query), source.language, NewsPageSize, page, apiKey)
 
-	log.Printf("Fetching general news from: %s", url)
+	log.Printf("Fetching general news from: %s", redactURL(requestURL))
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(url)
+	// Ошибка клиента содержит URL с ключом API, пользователю ее показывать нельзя
+	resp, err := httpClient.Get(requestURL)
 	if err != nil {
-		return "", err
+		return newsResult{}, i18n.NewError("news.err.connection")
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return "", nil
+		return newsResult{}, nil
 	}
 
 	var news NewsResponse
 	if err := json.NewDecoder(resp.Body).Decode(&news); err != nil {
-		return "", nil
+		return newsResult{}, nil
 	}
 
-	if news.Status != "ok" || len(news.Articles) == 0 {
-		return "", nil
+	if news.Status != "ok" {
+		return newsResult{}, nil
 	}
 
-	return formatNews(news.Articles), nil
+	return newsResult{Articles: convertArticles(news.Articles), Total: news.TotalResults}, nil
 }
 
-func formatNews(articles []Article) string {
-	result := "<b>Главные новости дня</b>\n\n"
-
-	for i, article := range articles {
-		if i >= 5 {
-			break
-		}
+// redactURL скрывает ключ API в адресе запроса, чтобы он не попал в лог
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "(invalid URL)"
+	}
+	query := u.Query()
+	if query.Has("apiKey") {
+		query.Set("apiKey", "REDACTED")
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
 
-		title := article.Title
-		if len(title) > 100 {
-			title = title[:97] + "..."
+// convertArticles переводит статьи NewsAPI в Article
+func convertArticles(items []NewsAPIArticle) []Article {
+	articles := make([]Article, 0, len(items))
+	for _, item := range items {
+		article := Article{
+			Title:  item.Title,
+			Source: item.Source.Name,
+			URL:    item.URL,
 		}
-
-		source := "Неизвестный источник"
-		if article.Source.Name != "" {
-			source = article.Source.Name
+		if item.Description != nil {
+			article.Description = *item.Description
 		}
-
-		result += fmt.Sprintf("<b>%d. %s</b>\n", i+1, title)
-
-		if article.Description != nil && *article.Description != "" {
-			description := *article.Description
-			if len(description) > 150 {
-				description = description[:147] + "..."
-			}
-			result += fmt.Sprintf("%s\n", description)
+		// Дата нужна не всем потребителям, поэтому неразобранная не считается ошибкой
+		if publishedAt, err := time.Parse(time.RFC3339, item.PublishedAt); err == nil {
+			article.PublishedAt = publishedAt
 		}
-
-		result += fmt.Sprintf("<i>Источник: %s</i>\n\n", source)
+		articles = append(articles, article)
 	}
-
-	result += "<i>Данные предоставлены NewsAPI</i>"
-	return result
-}
-
-func getNewsStub() string {
-	return `<b>Главные новости дня (демо-режим)</b>
-
-<b>1. Российские IT-специалисты показывают рост зарплат</b>
-Средняя зарплата разработчиков выросла на 15% за последний год согласно исследованию рекрутингового агентства.
-<i>Источник: РБК</i>
-
-<b>2. Удаленная работа становится стандартом для IT-сферы</b>
-85% российских IT-компаний готовы предоставить сотрудникам возможность полностью удаленной работы.
-<i>Источник: Ведомости</i>
-
-<b>3. Искусственный интеллект меняет рынок труда</b>
-Появляются новые профессии связанные с разработкой и внедрением ИИ-решений в российских компаниях.
-<i>Источник: Коммерсант</i>
-
-<b>4. Рост спроса на Go-разработчиков</b>
-Язык программирования Go показывает увеличение вакансий на 40% по сравнению с прошлым годом.
-<i>Источник: HeadHunter</i>
-
-<b>5. Новые меры поддержки IT-отрасли</b>
-Правительство анонсировало дополнительные льготы для IT-компаний и специалистов.
-<i>Источник: ТАСС</i>
-
-<i>Для получения актуальных новостей настройте NEWS_API_KEY</i>`
+	return articles
 }
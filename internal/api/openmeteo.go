@@ -0,0 +1,245 @@
+package api
+
+import (
+	"dailybot/internal/i18n"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Ответ Open-Meteo: текущая погода и прогноз по дням в одном запросе.
+// Значения по дням приходят параллельными массивами
+type openMeteoResponse struct {
+	UTCOffset int `json:"utc_offset_seconds"`
+	Current   struct {
+		Temperature float64 `json:"temperature_2m"`
+		FeelsLike   float64 `json:"apparent_temperature"`
+		Humidity    float64 `json:"relative_humidity_2m"`
+		WeatherCode int     `json:"weather_code"`
+		Pressure    float64 `json:"pressure_msl"`
+		WindSpeed   float64 `json:"wind_speed_10m"`
+	} `json:"current"`
+	Daily struct {
+		Time        []string  `json:"time"`
+		WeatherCode []int     `json:"weather_code"`
+		TempMax     []float64 `json:"temperature_2m_max"`
+		TempMin     []float64 `json:"temperature_2m_min"`
+		PrecipProb  []float64 `json:"precipitation_probability_max"` // проценты, может быть null
+	} `json:"daily"`
+}
+
+type openMeteoError struct {
+	Reason string `json:"reason"`
+}
+
+type openMeteoPlaces struct {
+	Results []struct {
+		Name        string  `json:"name"`
+		Latitude    float64 `json:"latitude"`
+		Longitude   float64 `json:"longitude"`
+		CountryCode string  `json:"country_code"`
+		Admin1      string  `json:"admin1"` // регион
+	} `json:"results"`
+}
+
+const (
+	openMeteoURL          = "https://api.open-meteo.com/v1/forecast"
+	openMeteoGeocodingURL = "https://geocoding-api.open-meteo.com/v1/search"
+)
+
+var (
+	openMeteoCache          = newCache[openMeteoResponse]("Open-Meteo", 10*time.Minute)
+	openMeteoGeocodingCache = newCache[[]Place]("Open-Meteo (геокодер)", 24*time.Hour)
+)
+
+// OpenMeteo - провайдер погоды и геокодер open-meteo.com. Ключ не нужен,
+// поэтому он работает всегда и подстраховывает OpenWeather
+type OpenMeteo struct{}
+
+func NewOpenMeteo() *OpenMeteo {
+	return &OpenMeteo{}
+}
+
+func (o *OpenMeteo) Name() string {
+	return "Open-Meteo"
+}
+
+// Current возвращает текущую погоду. Open-Meteo присылает код погоды WMO,
+// описание берется из каталога, поэтому язык в ключ кеша не входит
+func (o *OpenMeteo) Current(place Place, lang i18n.Lang, units Units) (Weather, error) {
+	result, err := o.get(place, units)
+	if err != nil {
+		return Weather{}, err
+	}
+
+	c := result.value.Current
+	return Weather{
+		Place:       place.title(),
+		Country:     place.Country,
+		Temp:        c.Temperature,
+		FeelsLike:   c.FeelsLike,
+		Description: wmoDescription(c.WeatherCode, lang),
+		Humidity:    int(c.Humidity),
+		WindSpeed:   c.WindSpeed,
+		Pressure:    int(c.Pressure),
+		Source:      o.Name(),
+		FetchedAt:   result.fetchedAt,
+		Stale:       result.stale,
+	}, nil
+}
+
+func (o *OpenMeteo) Forecast(place Place, lang i18n.Lang, units Units) (Forecast, error) {
+	result, err := o.get(place, units)
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	daily := result.value.Daily
+	loc := time.FixedZone("", result.value.UTCOffset)
+
+	var days []DayForecast
+	for i, date := range daily.Time {
+		if i >= len(daily.TempMin) || i >= len(daily.TempMax) || i >= len(daily.WeatherCode) {
+			break
+		}
+		day, err := time.ParseInLocation("2006-01-02", date, loc)
+		if err != nil {
+			return Forecast{}, i18n.NewError("weather.err.decode")
+		}
+
+		forecast := DayForecast{
+			Date:        day,
+			TempMin:     daily.TempMin[i],
+			TempMax:     daily.TempMax[i],
+			Description: wmoDescription(daily.WeatherCode[i], lang),
+		}
+		if i < len(daily.PrecipProb) {
+			forecast.PrecipMax = daily.PrecipProb[i] / 100
+		}
+		days = append(days, forecast)
+	}
+
+	return Forecast{
+		Place:     place.title(),
+		Country:   place.Country,
+		Days:      days,
+		Source:    o.Name(),
+		FetchedAt: result.fetchedAt,
+		Stale:     result.stale,
+	}, nil
+}
+
+func (o *OpenMeteo) get(place Place, units Units) (cached[openMeteoResponse], error) {
+	return openMeteoCache.get(coordinatesKey(place, string(units)), func() (openMeteoResponse, error) {
+		return fetchOpenMeteo(place, units)
+	})
+}
+
+func fetchOpenMeteo(place Place, units Units) (openMeteoResponse, error) {
+	var weather openMeteoResponse
+
+	lat, lon := roundedCoordinates(place)
+	params := url.Values{}
+	params.Add("latitude", lat)
+	params.Add("longitude", lon)
+	params.Add("current", "temperature_2m,apparent_temperature,relative_humidity_2m,weather_code,pressure_msl,wind_speed_10m")
+	params.Add("daily", "weather_code,temperature_2m_max,temperature_2m_min,precipitation_probability_max")
+	params.Add("forecast_days", strconv.Itoa(MaxForecastDays))
+	params.Add("timezone", "auto")
+	if units == Imperial {
+		params.Add("temperature_unit", "fahrenheit")
+		params.Add("wind_speed_unit", "mph")
+	} else {
+		params.Add("wind_speed_unit", "ms")
+	}
+
+	resp, err := httpClient.Get(openMeteoURL + "?" + params.Encode())
+	if err != nil {
+		return weather, i18n.NewError("weather.err.connection")
+	}
+	defer resp.Body.Close()
+
+	if err := checkOpenMeteoStatus(resp); err != nil {
+		return weather, err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&weather); err != nil {
+		return weather, i18n.NewError("weather.err.decode")
+	}
+
+	return weather, nil
+}
+
+// Geocode ищет места через геокодер Open-Meteo
+func (o *OpenMeteo) Geocode(query string, lang i18n.Lang) ([]Place, error) {
+	result, err := openMeteoGeocodingCache.get(normalizeKey(query, string(lang)), func() ([]Place, error) {
+		return fetchOpenMeteoPlaces(query, lang)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.value, nil
+}
+
+func fetchOpenMeteoPlaces(query string, lang i18n.Lang) ([]Place, error) {
+	params := url.Values{}
+	params.Add("name", query)
+	params.Add("count", strconv.Itoa(maxGeocodingResults))
+	params.Add("language", string(lang))
+	params.Add("format", "json")
+
+	resp, err := httpClient.Get(openMeteoGeocodingURL + "?" + params.Encode())
+	if err != nil {
+		return nil, i18n.NewError("weather.err.connection")
+	}
+	defer resp.Body.Close()
+
+	if err := checkOpenMeteoStatus(resp); err != nil {
+		return nil, err
+	}
+
+	// Если ничего не найдено, поля results в ответе нет
+	var found openMeteoPlaces
+	if err := json.NewDecoder(resp.Body).Decode(&found); err != nil {
+		return nil, i18n.NewError("weather.err.decode")
+	}
+
+	places := make([]Place, 0, len(found.Results))
+	for _, item := range found.Results {
+		places = append(places, Place{
+			Name:    item.Name,
+			State:   item.Admin1,
+			Country: item.CountryCode,
+			Lat:     item.Latitude,
+			Lon:     item.Longitude,
+		})
+	}
+
+	return uniquePlaces(places), nil
+}
+
+// checkOpenMeteoStatus переводит ошибку Open-Meteo в понятную: причину
+// сервис присылает в поле reason
+func checkOpenMeteoStatus(resp *http.Response) error {
+	if resp.StatusCode == 200 {
+		return nil
+	}
+
+	var errorResp openMeteoError
+	if err := json.NewDecoder(resp.Body).Decode(&errorResp); err == nil && errorResp.Reason != "" {
+		return i18n.NewError("weather.err.api", errorResp.Reason)
+	}
+	return i18n.NewError("weather.err.status", resp.StatusCode)
+}
+
+// wmoDescription - описание погоды по коду WMO. Неизвестный код - пустое
+// описание, при выводе подставляется "ясно"
+func wmoDescription(code int, lang i18n.Lang) string {
+	key := "wmo." + strconv.Itoa(code)
+	if _, ok := i18n.Lookup(i18n.Default, key); !ok {
+		return ""
+	}
+	return i18n.T(lang, key)
+}
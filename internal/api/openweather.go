@@ -0,0 +1,339 @@
+package api
+
+import (
+	"dailybot/internal/i18n"
+	"encoding/json"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type WeatherResponse struct {
+	Coord struct {
+		Lon float64 `json:"lon"`
+		Lat float64 `json:"lat"`
+	} `json:"coord"`
+	Weather []struct {
+		ID          int    `json:"id"`
+		Main        string `json:"main"`
+		Description string `json:"description"`
+		Icon        string `json:"icon"`
+	} `json:"weather"`
+	Base string `json:"base"`
+	Main struct {
+		Temp      float64 `json:"temp"`
+		FeelsLike float64 `json:"feels_like"`
+		TempMin   float64 `json:"temp_min"`
+		TempMax   float64 `json:"temp_max"`
+		Pressure  int     `json:"pressure"`
+		Humidity  int     `json:"humidity"`
+	} `json:"main"`
+	Visibility int `json:"visibility"`
+	Wind       struct {
+		Speed float64 `json:"speed"`
+		Deg   int     `json:"deg"`
+	} `json:"wind"`
+	Clouds struct {
+		All int `json:"all"`
+	} `json:"clouds"`
+	Dt  int64 `json:"dt"`
+	Sys struct {
+		Type    int    `json:"type"`
+		ID      int    `json:"id"`
+		Country string `json:"country"`
+		Sunrise int64  `json:"sunrise"`
+		Sunset  int64  `json:"sunset"`
+	} `json:"sys"`
+	Timezone int    `json:"timezone"`
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Cod      int    `json:"cod"`
+}
+
+type ForecastResponse struct {
+	Cod  string `json:"cod"`
+	List []struct {
+		Dt   int64 `json:"dt"`
+		Main struct {
+			Temp    float64 `json:"temp"`
+			TempMin float64 `json:"temp_min"`
+			TempMax float64 `json:"temp_max"`
+		} `json:"main"`
+		Weather []struct {
+			Description string `json:"description"`
+		} `json:"weather"`
+		Pop float64 `json:"pop"` // вероятность осадков 0..1
+	} `json:"list"`
+	City struct {
+		Name     string `json:"name"`
+		Country  string `json:"country"`
+		Timezone int    `json:"timezone"` // смещение от UTC в секундах
+	} `json:"city"`
+}
+
+type ErrorResponse struct {
+	Cod     int    `json:"cod"`
+	Message string `json:"message"`
+}
+
+type geocodingResponse []struct {
+	Name       string            `json:"name"`
+	LocalNames map[string]string `json:"local_names"`
+	Lat        float64           `json:"lat"`
+	Lon        float64           `json:"lon"`
+	Country    string            `json:"country"`
+	State      string            `json:"state"`
+}
+
+const openWeatherURL = "https://api.openweathermap.org"
+
+var (
+	weatherCache  = newCache[WeatherResponse]("OpenWeather", 10*time.Minute)
+	forecastCache = newCache[ForecastResponse]("OpenWeather (прогноз)", 30*time.Minute)
+	// Названия городов меняются редко, поэтому кешируем надолго
+	geocodingCache = newCache[[]Place]("OpenWeather (геокодер)", 24*time.Hour)
+)
+
+// OpenWeather - провайдер погоды и геокодер openweathermap.org, нужен API ключ
+type OpenWeather struct {
+	apiKey string
+}
+
+func NewOpenWeather(apiKey string) *OpenWeather {
+	return &OpenWeather{apiKey: apiKey}
+}
+
+func (o *OpenWeather) Name() string {
+	return "OpenWeather"
+}
+
+// Current возвращает текущую погоду. Описание погоды OpenWeather присылает
+// на языке lang, поэтому язык и единицы входят в ключ кеша.
+// В заголовке показываем название и регион места, а не ближайшей метеостанции
+func (o *OpenWeather) Current(place Place, lang i18n.Lang, units Units) (Weather, error) {
+	result, err := weatherCache.get(coordinatesKey(place, string(lang), string(units)), func() (WeatherResponse, error) {
+		var weather WeatherResponse
+		if err := o.fetch("/data/2.5/weather", place, lang, units, &weather); err != nil {
+			return weather, err
+		}
+		// Проверяем код ответа в JSON
+		if weather.Cod != 200 {
+			return weather, i18n.NewError("weather.err.response")
+		}
+		return weather, nil
+	})
+	if err != nil {
+		return Weather{}, err
+	}
+
+	w := result.value
+	weather := Weather{
+		Place:     place.title(),
+		Country:   place.Country,
+		Temp:      w.Main.Temp,
+		FeelsLike: w.Main.FeelsLike,
+		Humidity:  w.Main.Humidity,
+		WindSpeed: w.Wind.Speed,
+		Pressure:  w.Main.Pressure,
+		Source:    o.Name(),
+		FetchedAt: result.fetchedAt,
+		Stale:     result.stale,
+	}
+	if len(w.Weather) > 0 {
+		weather.Description = w.Weather[0].Description
+	}
+	// Место по геопозиции называем по ближайшему городу из ответа
+	if place.Name == "" && w.Name != "" {
+		weather.Place, weather.Country = w.Name, w.Sys.Country
+	}
+
+	return weather, nil
+}
+
+// Forecast возвращает прогноз, собранный из трехчасовых слотов по дням
+func (o *OpenWeather) Forecast(place Place, lang i18n.Lang, units Units) (Forecast, error) {
+	result, err := forecastCache.get(coordinatesKey(place, string(lang), string(units)), func() (ForecastResponse, error) {
+		var forecast ForecastResponse
+		if err := o.fetch("/data/2.5/forecast", place, lang, units, &forecast); err != nil {
+			return forecast, err
+		}
+		if forecast.Cod != "200" || len(forecast.List) == 0 {
+			return forecast, i18n.NewError("forecast.err.response")
+		}
+		return forecast, nil
+	})
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	forecast := Forecast{
+		Place:     place.title(),
+		Country:   place.Country,
+		Days:      aggregateForecast(result.value, MaxForecastDays),
+		Source:    o.Name(),
+		FetchedAt: result.fetchedAt,
+		Stale:     result.stale,
+	}
+	if place.Name == "" && result.value.City.Name != "" {
+		forecast.Place, forecast.Country = result.value.City.Name, result.value.City.Country
+	}
+
+	return forecast, nil
+}
+
+// fetch запрашивает погоду или прогноз по координатам места
+func (o *OpenWeather) fetch(path string, place Place, lang i18n.Lang, units Units, target any) error {
+	lat, lon := roundedCoordinates(place)
+	params := url.Values{}
+	params.Add("lat", lat)
+	params.Add("lon", lon)
+	params.Add("appid", o.apiKey)
+	params.Add("units", string(units))
+	params.Add("lang", string(lang))
+
+	resp, err := httpClient.Get(openWeatherURL + path + "?" + params.Encode())
+	if err != nil {
+		return i18n.NewError("weather.err.connection")
+	}
+	defer resp.Body.Close()
+
+	if err := checkWeatherStatus(resp, place.title()); err != nil {
+		return err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return i18n.NewError("weather.err.decode")
+	}
+	return nil
+}
+
+// Geocode ищет места через геокодер OpenWeather. Название берется на языке
+// пользователя, если геокодер его знает
+func (o *OpenWeather) Geocode(query string, lang i18n.Lang) ([]Place, error) {
+	result, err := geocodingCache.get(normalizeKey(query, string(lang)), func() ([]Place, error) {
+		return o.fetchPlaces(query, lang)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.value, nil
+}
+
+func (o *OpenWeather) fetchPlaces(query string, lang i18n.Lang) ([]Place, error) {
+	params := url.Values{}
+	params.Add("q", query)
+	params.Add("limit", strconv.Itoa(maxGeocodingResults))
+	params.Add("appid", o.apiKey)
+
+	resp, err := httpClient.Get(openWeatherURL + "/geo/1.0/direct?" + params.Encode())
+	if err != nil {
+		return nil, i18n.NewError("weather.err.connection")
+	}
+	defer resp.Body.Close()
+
+	if err := checkWeatherStatus(resp, query); err != nil {
+		return nil, err
+	}
+
+	var found geocodingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&found); err != nil {
+		return nil, i18n.NewError("weather.err.decode")
+	}
+
+	places := make([]Place, 0, len(found))
+	for _, item := range found {
+		place := Place{
+			Name:    item.Name,
+			State:   item.State,
+			Country: item.Country,
+			Lat:     item.Lat,
+			Lon:     item.Lon,
+		}
+		if local := item.LocalNames[string(lang)]; local != "" {
+			place.Name = local
+		}
+		places = append(places, place)
+	}
+
+	return uniquePlaces(places), nil
+}
+
+// checkWeatherStatus переводит HTTP-статус ответа OpenWeather в понятную ошибку
+func checkWeatherStatus(resp *http.Response, city string) error {
+	if resp.StatusCode == 404 {
+		return i18n.NewError("weather.err.city_not_found", html.EscapeString(city))
+	}
+
+	if resp.StatusCode == 401 {
+		return i18n.NewError("weather.err.bad_key")
+	}
+
+	if resp.StatusCode != 200 {
+		// Пытаемся получить детальную ошибку
+		var errorResp ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err == nil {
+			return i18n.NewError("weather.err.api", errorResp.Message)
+		}
+		return i18n.NewError("weather.err.status", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// aggregateForecast группирует трехчасовые слоты по дням в часовом поясе города
+func aggregateForecast(f ForecastResponse, days int) []DayForecast {
+	loc := time.FixedZone(f.City.Name, f.City.Timezone)
+
+	var result []DayForecast
+	var descriptions []map[string]int
+
+	for _, slot := range f.List {
+		t := time.Unix(slot.Dt, 0).In(loc)
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+
+		if len(result) == 0 || !result[len(result)-1].Date.Equal(day) {
+			if len(result) == days {
+				break
+			}
+			descriptions = append(descriptions, make(map[string]int))
+			result = append(result, DayForecast{
+				Date:    day,
+				TempMin: slot.Main.TempMin,
+				TempMax: slot.Main.TempMax,
+			})
+		}
+
+		current := &result[len(result)-1]
+		if slot.Main.TempMin < current.TempMin {
+			current.TempMin = slot.Main.TempMin
+		}
+		if slot.Main.TempMax > current.TempMax {
+			current.TempMax = slot.Main.TempMax
+		}
+		if slot.Pop > current.PrecipMax {
+			current.PrecipMax = slot.Pop
+		}
+		if len(slot.Weather) > 0 {
+			descriptions[len(descriptions)-1][slot.Weather[0].Description]++
+		}
+	}
+
+	for i := range result {
+		result[i].Description = dominantDescription(descriptions[i])
+	}
+	return result
+}
+
+// dominantDescription выбирает самое частое описание за день.
+// Пустая строка - описаний не было, при выводе подставляется "ясно"
+func dominantDescription(counts map[string]int) string {
+	best, bestCount := "", 0
+	for description, count := range counts {
+		if count > bestCount || (count == bestCount && description < best) {
+			best, bestCount = description, count
+		}
+	}
+	return best
+}
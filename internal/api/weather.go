@@ -1,153 +1,223 @@
 package api
 
 import (
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"net/url"
+	"dailybot/internal/i18n"
+	"html"
+	"log"
 	"time"
 )
 
-type WeatherResponse struct {
-	Coord struct {
-		Lon float64 `json:"lon"`
-		Lat float64 `json:"lat"`
-	} `json:"coord"`
-	Weather []struct {
-		ID          int    `json:"id"`
-		Main        string `json:"main"`
-		Description string `json:"description"`
-		Icon        string `json:"icon"`
-	} `json:"weather"`
-	Base string `json:"base"`
-	Main struct {
-		Temp      float64 `json:"temp"`
-		FeelsLike float64 `json:"feels_like"`
-		TempMin   float64 `json:"temp_min"`
-		TempMax   float64 `json:"temp_max"`
-		Pressure  int     `json:"pressure"`
-		Humidity  int     `json:"humidity"`
-	} `json:"main"`
-	Visibility int `json:"visibility"`
-	Wind       struct {
-		Speed float64 `json:"speed"`
-		Deg   int     `json:"deg"`
-	} `json:"wind"`
-	Clouds struct {
-		All int `json:"all"`
-	} `json:"clouds"`
-	Dt  int64 `json:"dt"`
-	Sys struct {
-		Type    int    `json:"type"`
-		ID      int    `json:"id"`
-		Country string `json:"country"`
-		Sunrise int64  `json:"sunrise"`
-		Sunset  int64  `json:"sunset"`
-	} `json:"sys"`
-	Timezone int    `json:"timezone"`
-	ID       int    `json:"id"`
-	Name     string `json:"name"`
-	Cod      int    `json:"cod"`
+// Units - система единиц: провайдеры сами пересчитывают температуру и ветер
+type Units string
+
+const (
+	Metric   Units = "metric"   // °C, м/с
+	Imperial Units = "imperial" // °F, мили в час
+)
+
+// ParseUnits разбирает сохраненное значение, по умолчанию - метрическая система
+func ParseUnits(value string) Units {
+	if Units(value) == Imperial {
+		return Imperial
+	}
+	return Metric
+}
+
+// Weather - текущая погода в виде, не зависящем от провайдера
+type Weather struct {
+	Place       string // название места или координаты
+	Country     string // может быть пустым
+	Temp        float64
+	FeelsLike   float64
+	Description string // пустое - "ясно"
+	Humidity    int
+	WindSpeed   float64
+	Pressure    int // гПа
+	Units       Units
+
+	Source    string // провайдер, от которого получены данные
+	FetchedAt time.Time
+	Stale     bool // провайдер недоступен, данные из кеша
+	Demo      bool // выдуманные данные демо-режима
+}
+
+// Forecast - прогноз по дням начиная с сегодняшнего в часовом поясе места
+type Forecast struct {
+	Place    string
+	Country  string
+	Days     []DayForecast
+	Units    Units
+	Tomorrow bool // прогноз только на завтра
+
+	Source    string
+	FetchedAt time.Time
+	Stale     bool
+	Demo      bool
+}
+
+// WeatherProvider - источник погоды. Место к этому моменту уже найдено
+// геокодером, поэтому провайдер работает с координатами
+type WeatherProvider interface {
+	Name() string
+	Current(place Place, lang i18n.Lang, units Units) (Weather, error)
+	Forecast(place Place, lang i18n.Lang, units Units) (Forecast, error)
+}
+
+// Geocoder - поиск мест по названию. Обычно это тот же провайдер погоды
+type Geocoder interface {
+	Name() string
+	Geocode(query string, lang i18n.Lang) ([]Place, error)
 }
 
-type ErrorResponse struct {
-	Cod     int    `json:"cod"`
-	Message string `json:"message"`
+// WeatherService опрашивает провайдеров по порядку: если первый недоступен,
+// ответ берется у следующего, а в ответе указывается, откуда данные
+type WeatherService struct {
+	providers []WeatherProvider
+	geocoders []Geocoder
+	demo      bool
 }
 
-func GetWeather(city, apiKey string) (string, error) {
-	if apiKey == "" {
-		return getWeatherStub(city), nil
+// NewWeatherService собирает цепочку провайдеров. Провайдеры, которые умеют
+// искать места, становятся и геокодерами в том же порядке
+func NewWeatherService(providers ...WeatherProvider) *WeatherService {
+	s := &WeatherService{providers: providers}
+	for _, provider := range providers {
+		if geocoder, ok := provider.(Geocoder); ok {
+			s.geocoders = append(s.geocoders, geocoder)
+		}
 	}
+	return s
+}
 
-	baseURL := "https://api.openweathermap.org/data/2.5/weather"
-	params := url.Values{}
-	params.Add("q", city)
-	params.Add("appid", apiKey)
-	params.Add("units", "metric")
-	params.Add("lang", "ru")
+// NewDemoWeatherService - сервис-заглушка с выдуманными данными, только для
+// демо-режима: в ответах явно написано, что данные ненастоящие
+func NewDemoWeatherService() *WeatherService {
+	return &WeatherService{demo: true}
+}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(baseURL + "?" + params.Encode())
-	if err != nil {
-		return "", fmt.Errorf("ошибка соединения с сервисом погоды")
+// Providers - названия провайдеров в порядке опроса
+func (s *WeatherService) Providers() []string {
+	if s.demo {
+		return []string{"demo"}
 	}
-	defer resp.Body.Close()
 
-	// Проверяем статус ответа
-	if resp.StatusCode == 404 {
-		return "", fmt.Errorf("город '%s' не найден", city)
+	names := make([]string, 0, len(s.providers))
+	for _, provider := range s.providers {
+		names = append(names, provider.Name())
 	}
+	return names
+}
 
-	if resp.StatusCode == 401 {
-		return "", fmt.Errorf("неверный API ключ OpenWeather")
+// Geocode ищет населенные пункты по названию. Несколько результатов -
+// название неоднозначно, пустой список - ничего не найдено.
+// В демо-режиме возвращает одно место без координат
+func (s *WeatherService) Geocode(query string, lang i18n.Lang) ([]Place, error) {
+	if s.demo {
+		return []Place{{Name: query}}, nil
 	}
 
-	if resp.StatusCode != 200 {
-		// Пытаемся получить детальную ошибку
-		var errorResp ErrorResponse
-		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err == nil {
-			return "", fmt.Errorf("ошибка API: %s", errorResp.Message)
+	var firstErr error
+	for _, geocoder := range s.geocoders {
+		places, err := geocoder.Geocode(query, lang)
+		if err != nil {
+			log.Printf("Geocoder %s failed for %q: %v", geocoder.Name(), query, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
 		}
-		return "", fmt.Errorf("ошибка сервиса погоды (код %d)", resp.StatusCode)
-	}
 
-	var weather WeatherResponse
-	if err := json.NewDecoder(resp.Body).Decode(&weather); err != nil {
-		return "", fmt.Errorf("ошибка обработки данных о погоде")
+		for _, place := range places {
+			rememberCity(place.Name)
+		}
+		return places, nil
 	}
 
-	// Проверяем код ответа в JSON
-	if weather.Cod != 200 {
-		return "", fmt.Errorf("ошибка получения данных о погоде")
+	if firstErr == nil {
+		firstErr = i18n.NewError("weather.err.response")
 	}
-
-	return formatWeather(weather), nil
+	return nil, firstErr
 }
 
-func formatWeather(w WeatherResponse) string {
-	temp := int(w.Main.Temp)
-	feelsLike := int(w.Main.FeelsLike)
+// Locate - первое место, найденное по названию
+func (s *WeatherService) Locate(query string, lang i18n.Lang) (Place, error) {
+	places, err := s.Geocode(query, lang)
+	if err != nil {
+		return Place{}, err
+	}
+	if len(places) == 0 {
+		return Place{}, i18n.NewError("weather.err.city_not_found", html.EscapeString(query))
+	}
+	return places[0], nil
+}
 
-	description := "ясно"
-	if len(w.Weather) > 0 {
-		description = w.Weather[0].Description
+// Current возвращает текущую погоду в месте
+func (s *WeatherService) Current(place Place, lang i18n.Lang, units Units) (Weather, error) {
+	if s.demo {
+		return Weather{Place: place.title(), Units: Metric, Demo: true}, nil
 	}
 
-	result := fmt.Sprintf(`<b>Погода в городе %s, %s</b>
+	units = ParseUnits(string(units))
+	weather, err := firstAvailable(s.providers, func(p WeatherProvider) (Weather, bool, error) {
+		w, err := p.Current(place, lang, units)
+		return w, w.Stale, err
+	})
+	if err != nil {
+		return Weather{}, err
+	}
 
-<b>Температура:</b> %d°C (ощущается как %d°C)
-<b>Описание:</b> %s
-<b>Влажность:</b> %d%%`,
-		w.Name,
-		w.Sys.Country,
-		temp,
-		feelsLike,
-		description,
-		w.Main.Humidity)
+	weather.Units = units
+	return weather, nil
+}
 
-	// Добавляем дополнительные данные если они есть
-	if w.Wind.Speed > 0 {
-		windSpeed := int(w.Wind.Speed)
-		result += fmt.Sprintf("\n<b>Ветер:</b> %d м/с", windSpeed)
+// PlaceName возвращает название места по координатам. Если провайдер
+// названия не знает (или демо-режим) - сами координаты
+func (s *WeatherService) PlaceName(lat, lon float64, lang i18n.Lang) (string, error) {
+	place := Place{Lat: lat, Lon: lon}
+	if s.demo {
+		return place.title(), nil
 	}
 
-	if w.Main.Pressure > 0 {
-		pressureMmHg := int(float64(w.Main.Pressure) * 0.75006)
-		result += fmt.Sprintf("\n<b>Давление:</b> %d мм рт.ст.", pressureMmHg)
+	weather, err := firstAvailable(s.providers, func(p WeatherProvider) (Weather, bool, error) {
+		w, err := p.Current(place, lang, Metric)
+		return w, w.Stale, err
+	})
+	if err != nil {
+		return "", err
 	}
-
-	return result
+	return weather.Place, nil
 }
 
-func getWeatherStub(city string) string {
-	return fmt.Sprintf(`<b>Погода в городе %s (демо-режим)</b>
-
-<b>Температура:</b> 22°C (ощущается как 24°C)
-<b>Описание:</b> переменная облачность
-<b>Влажность:</b> 65%%
-<b>Ветер:</b> 3 м/с
-<b>Давление:</b> 760 мм рт.ст.
+// firstAvailable опрашивает провайдеров по порядку до первого свежего ответа.
+// Устаревшие данные из кеша отдаются, только если свежих нет ни у кого,
+// а если не ответил никто - возвращается ошибка первого провайдера
+func firstAvailable[T any](providers []WeatherProvider, request func(WeatherProvider) (T, bool, error)) (T, error) {
+	var fallback T
+	var hasFallback bool
+	var firstErr error
+
+	for _, provider := range providers {
+		result, stale, err := request(provider)
+		if err != nil {
+			log.Printf("Weather provider %s failed: %v", provider.Name(), err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if !stale {
+			return result, nil
+		}
+		if !hasFallback {
+			fallback, hasFallback = result, true
+		}
+	}
 
-<i>Для получения реальных данных настройте OPENWEATHER_API_KEY</i>`, city)
+	if hasFallback {
+		return fallback, nil
+	}
+	if firstErr == nil {
+		firstErr = i18n.NewError("weather.err.response")
+	}
+	return fallback, firstErr
 }
@@ -0,0 +1,348 @@
+package bot
+
+import (
+	"context"
+	"dailybot/internal/api"
+	"dailybot/internal/i18n"
+	"dailybot/internal/storage"
+	"fmt"
+	"html"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	alertPollInterval = 10 * time.Minute
+	maxAlertsPerChat  = 10
+)
+
+var alertRe = regexp.MustCompile(`(?i)^([A-Z]{3})\s*(>|<|change)\s*(\d+(?:[.,]\d+)?)\s*%?$`)
+
+func (b *Bot) loadAlerts() {
+	if b.store == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	alerts, err := b.store.ListAlerts(ctx)
+	if err != nil {
+		log.Printf("Failed to load alerts: %v", err)
+		return
+	}
+
+	b.alertsMu.Lock()
+	for _, alert := range alerts {
+		b.alerts[alert.ID] = alert
+	}
+	b.alertsMu.Unlock()
+
+	log.Printf("Loaded %d exchange rate alerts", len(alerts))
+}
+
+func (b *Bot) handleAlert(chatID int64, args string) {
+	lang := b.lang(chatID)
+	args = strings.TrimSpace(args)
+	usage := i18n.T(lang, "alert.usage")
+
+	if fields := strings.Fields(args); len(fields) == 2 && strings.EqualFold(fields[0], "delete") {
+		id, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			b.sendMessage(chatID, usage)
+			return
+		}
+		b.deleteAlert(chatID, id)
+		return
+	}
+
+	match := alertRe.FindStringSubmatch(args)
+	if match == nil {
+		b.sendMessage(chatID, usage)
+		return
+	}
+
+	threshold, err := strconv.ParseFloat(strings.ReplaceAll(match[3], ",", "."), 64)
+	if err != nil || threshold <= 0 {
+		b.sendMessage(chatID, usage)
+		return
+	}
+
+	alert := storage.Alert{
+		ChatID:    chatID,
+		Currency:  strings.ToUpper(match[1]),
+		Threshold: threshold,
+	}
+	switch strings.ToLower(match[2]) {
+	case ">":
+		alert.Kind = storage.AlertAbove
+	case "<":
+		alert.Kind = storage.AlertBelow
+	case "change":
+		alert.Kind = storage.AlertChange
+	}
+
+	if b.countAlerts(chatID) >= maxAlertsPerChat {
+		limit := i18n.T(lang, "alert.err.limit", maxAlertsPerChat, i18n.Plural(lang, "plural.alert", maxAlertsPerChat))
+		b.sendMessage(chatID, i18n.T(lang, "error", limit))
+		return
+	}
+
+	// Начальное состояние берем из текущего снимка, чтобы оповещение
+	// сработало только на следующем пересечении, а не сразу после создания
+	rates, err := api.FetchExchangeRates()
+	if err != nil {
+		b.sendMessage(chatID, i18n.T(lang, "error", i18n.ErrorText(lang, err)))
+		return
+	}
+
+	currency, exists := rates.Valute[alert.Currency]
+	if !exists {
+		b.sendMessage(chatID, i18n.T(lang, "error", i18n.T(lang, "exchange.err.currency_not_found", html.EscapeString(alert.Currency))))
+		return
+	}
+
+	alert.Triggered, _ = evaluateAlert(alert, currency)
+	alert.LastDate = rates.Date
+
+	if err := b.saveAlert(&alert); err != nil {
+		log.Printf("Failed to save alert for chat %d: %v", chatID, err)
+		b.sendMessage(chatID, i18n.T(lang, "error", i18n.T(lang, "alert.err.save")))
+		return
+	}
+
+	rate, _ := unitRate(currency)
+	b.sendMessage(chatID, i18n.T(lang, "alert.created", alert.ID, describeAlert(alert, lang), rate))
+}
+
+func (b *Bot) handleAlerts(chatID int64) {
+	lang := b.lang(chatID)
+
+	b.alertsMu.Lock()
+	var alerts []storage.Alert
+	for _, alert := range b.alerts {
+		if alert.ChatID == chatID {
+			alerts = append(alerts, alert)
+		}
+	}
+	b.alertsMu.Unlock()
+
+	if len(alerts) == 0 {
+		b.sendMessage(chatID, i18n.T(lang, "alert.none"))
+		return
+	}
+
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].ID < alerts[j].ID })
+
+	text := i18n.T(lang, "alert.list_title")
+	for _, alert := range alerts {
+		text += fmt.Sprintf("<b>#%d</b> %s\n", alert.ID, describeAlert(alert, lang))
+	}
+	text += i18n.T(lang, "alert.list_footer")
+
+	b.sendMessage(chatID, text)
+}
+
+func (b *Bot) countAlerts(chatID int64) int {
+	b.alertsMu.Lock()
+	defer b.alertsMu.Unlock()
+
+	count := 0
+	for _, alert := range b.alerts {
+		if alert.ChatID == chatID {
+			count++
+		}
+	}
+	return count
+}
+
+func (b *Bot) saveAlert(alert *storage.Alert) error {
+	if b.store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		id, err := b.store.CreateAlert(ctx, *alert)
+		if err != nil {
+			return err
+		}
+		alert.ID = id
+	}
+
+	b.alertsMu.Lock()
+	defer b.alertsMu.Unlock()
+
+	if b.store == nil {
+		b.nextAlertID++
+		alert.ID = b.nextAlertID
+	}
+	b.alerts[alert.ID] = *alert
+
+	return nil
+}
+
+func (b *Bot) deleteAlert(chatID, id int64) {
+	lang := b.lang(chatID)
+
+	b.alertsMu.Lock()
+	alert, exists := b.alerts[id]
+	if exists && alert.ChatID == chatID {
+		delete(b.alerts, id)
+	}
+	b.alertsMu.Unlock()
+
+	if !exists || alert.ChatID != chatID {
+		b.sendMessage(chatID, i18n.T(lang, "alert.not_found", id))
+		return
+	}
+
+	if b.store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := b.store.DeleteAlert(ctx, chatID, id); err != nil {
+			log.Printf("Failed to delete alert %d: %v", id, err)
+		}
+	}
+
+	b.sendMessage(chatID, i18n.T(lang, "alert.deleted", id))
+}
+
+// runAlertPoller периодически сверяет свежий снимок ЦБ с оповещениями
+func (b *Bot) runAlertPoller() {
+	defer b.background.Done()
+
+	ticker := time.NewTicker(alertPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.checkAlerts()
+		case <-b.stopping:
+			return
+		}
+	}
+}
+
+func (b *Bot) checkAlerts() {
+	b.alertsMu.Lock()
+	empty := len(b.alerts) == 0
+	b.alertsMu.Unlock()
+	if empty {
+		return
+	}
+
+	rates, err := api.FetchExchangeRates()
+	if err != nil {
+		log.Printf("Alert poller: %v", err)
+		return
+	}
+
+	type firing struct {
+		alert storage.Alert
+		rate  api.Currency
+	}
+	var fired []firing
+	var changed []storage.Alert
+
+	b.alertsMu.Lock()
+	for id, alert := range b.alerts {
+		currency, exists := rates.Valute[alert.Currency]
+		if !exists {
+			continue
+		}
+
+		holds, fire := evaluateAlert(alert, currency)
+		if alert.Kind == storage.AlertChange {
+			// Изменение считаем один раз на каждый новый снимок ЦБ
+			if alert.LastDate == rates.Date {
+				continue
+			}
+			alert.LastDate = rates.Date
+			fire = holds
+		} else if holds == alert.Triggered {
+			continue
+		}
+
+		alert.Triggered = holds
+		b.alerts[id] = alert
+		changed = append(changed, alert)
+
+		if fire {
+			fired = append(fired, firing{alert: alert, rate: currency})
+		}
+	}
+	b.alertsMu.Unlock()
+
+	// Сначала сохраняем состояние: после рестарта оповещение не должно сработать повторно
+	if b.store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		for _, alert := range changed {
+			if err := b.store.UpdateAlertState(ctx, alert); err != nil {
+				log.Printf("Failed to update alert %d: %v", alert.ID, err)
+			}
+		}
+		cancel()
+	}
+
+	for _, f := range fired {
+		b.sendMessage(f.alert.ChatID, formatAlertFired(f.alert, f.rate, b.lang(f.alert.ChatID)))
+	}
+}
+
+// evaluateAlert возвращает, выполняется ли условие на снимке,
+// и нужно ли отправить оповещение при пороговом пересечении
+func evaluateAlert(alert storage.Alert, currency api.Currency) (holds, fire bool) {
+	rate, previous := unitRate(currency)
+
+	switch alert.Kind {
+	case storage.AlertAbove:
+		holds = rate > alert.Threshold
+	case storage.AlertBelow:
+		holds = rate < alert.Threshold
+	case storage.AlertChange:
+		if previous > 0 {
+			holds = math.Abs(rate-previous)/previous*100 >= alert.Threshold
+		}
+	}
+	return holds, holds && !alert.Triggered
+}
+
+// unitRate - текущий и вчерашний курс за одну единицу валюты. ЦБ РФ
+// котирует часть валют за 10 или 100 единиц (JPY, KZT), а пороги
+// оповещений, как и /convert, считаются за одну
+func unitRate(currency api.Currency) (rate, previous float64) {
+	nominal := float64(max(currency.Nominal, 1))
+	return currency.Value / nominal, currency.Previous / nominal
+}
+
+func describeAlert(alert storage.Alert, lang i18n.Lang) string {
+	switch alert.Kind {
+	case storage.AlertAbove:
+		return i18n.T(lang, "alert.above", alert.Currency, alert.Threshold)
+	case storage.AlertBelow:
+		return i18n.T(lang, "alert.below", alert.Currency, alert.Threshold)
+	default:
+		return i18n.T(lang, "alert.change", alert.Currency, alert.Threshold)
+	}
+}
+
+func formatAlertFired(alert storage.Alert, currency api.Currency, lang i18n.Lang) string {
+	rate, previous := unitRate(currency)
+	change := rate - previous
+	sign := ""
+	if change > 0 {
+		sign = "+"
+	}
+
+	return i18n.T(lang, "alert.fired",
+		alert.ID,
+		describeAlert(alert, lang),
+		rate,
+		sign, change)
+}
@@ -1,8 +1,20 @@
 package bot
 
 import (
+	"context"
+	"dailybot/internal/api"
 	"dailybot/internal/config"
+	"dailybot/internal/i18n"
+	"dailybot/internal/metrics"
+	"dailybot/internal/ratelimit"
+	"dailybot/internal/render"
+	"dailybot/internal/storage"
 	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -15,37 +27,270 @@ type Bot struct {
 	api    *tgbotapi.BotAPI
 	config *config.Config
 	admin  AdminLogger
+	store  *storage.Storage // nil - данные живут только в памяти
+
+	limiter *ratelimit.Limiter
+	weather *api.WeatherService
+
+	subsMu      sync.RWMutex
+	subs        map[int64]storage.Subscription
+	digestRetry map[int64]time.Time // когда повторить неотправленный дайджест
+
+	alertsMu    sync.Mutex
+	alerts      map[int64]storage.Alert
+	nextAlertID int64 // счетчик ID оповещений, когда базы нет
+
+	profilesMu    sync.RWMutex
+	profiles      map[int64]storage.Profile
+	detectedLangs map[int64]detectedLang // язык из Telegram, пока пользователь не выбрал свой
+	langsPrunedAt time.Time              // когда из detectedLangs последний раз убирали старые записи
+	pendingInput  map[int64]pendingInput // поле профиля, значение которого ждем следующим сообщением
+
+	placesMu     sync.Mutex
+	placeChoices map[int64]map[string]api.Place // выбор среди одноименных мест по запросу
+	placeOffers  map[int64]placeOffer           // последний предложенный чату выбор
+
+	// Заполняются в startWebhook, nil - вебхук не запущен
+	webhookMu      sync.Mutex
+	webhookServer  *http.Server
+	webhookUpdates chan tgbotapi.Update
+
+	lastPoll atomic.Int64 // UnixNano последнего обращения за обновлениями
+
+	dispatcher *dispatcher
+	inline     *inlineDebouncer
+
+	// Остановка: stopping закрывается в Stop, loopDone - когда Start
+	// перестает принимать обновления, background - фоновые задачи
+	stopping   chan struct{}
+	stopOnce   sync.Once
+	loopDone   chan struct{}
+	background sync.WaitGroup
 }
 
-func New(cfg *config.Config, adminLogger AdminLogger) (*Bot, error) {
-	api, err := tgbotapi.NewBotAPI(cfg.TelegramToken)
+func New(cfg *config.Config, adminLogger AdminLogger, store *storage.Storage, limiter *ratelimit.Limiter) (*Bot, error) {
+	botAPI, err := tgbotapi.NewBotAPI(cfg.TelegramToken)
 	if err != nil {
 		return nil, err
 	}
 
-	api.Debug = false
-	log.Printf("Bot authorized as @%s", api.Self.UserName)
+	botAPI.Debug = false
+	botAPI.Client = metrics.TelegramClient{Next: botAPI.Client}
+	log.Printf("Bot authorized as @%s", botAPI.Self.UserName)
+
+	b := &Bot{
+		api:     botAPI,
+		config:  cfg,
+		admin:   adminLogger,
+		store:   store,
+		limiter: limiter,
+		weather: newWeatherService(cfg),
+		subs:    make(map[int64]storage.Subscription),
+		alerts:  make(map[int64]storage.Alert),
+
+		digestRetry: make(map[int64]time.Time),
+
+		profiles:      make(map[int64]storage.Profile),
+		detectedLangs: make(map[int64]detectedLang),
+		pendingInput:  make(map[int64]pendingInput),
+
+		placeChoices: make(map[int64]map[string]api.Place),
+		placeOffers:  make(map[int64]placeOffer),
+
+		stopping: make(chan struct{}),
+		loopDone: make(chan struct{}),
+	}
+	b.dispatcher = newDispatcher(cfg.Workers, cfg.QueueSize, b.handleUpdate)
+	b.inline = newInlineDebouncer()
+
+	b.loadSubscriptions()
+	b.loadAlerts()
+	b.loadProfiles()
+	b.loadPlaceChoices()
+	b.loadRateLimits()
+
+	return b, nil
+}
+
+// newWeatherService - OpenWeather, если есть ключ, и Open-Meteo как запасной
+// провайдер, которому ключ не нужен. Заглушка - только в демо-режиме
+func newWeatherService(cfg *config.Config) *api.WeatherService {
+	if cfg.DemoMode {
+		log.Printf("⚠️ Demo mode: weather replies are fake")
+		return api.NewDemoWeatherService()
+	}
+
+	var providers []api.WeatherProvider
+	if cfg.OpenWeatherKey != "" {
+		providers = append(providers, api.NewOpenWeather(cfg.OpenWeatherKey))
+	}
+	providers = append(providers, api.NewOpenMeteo())
 
-	return &Bot{
-		api:    api,
-		config: cfg,
-		admin:  adminLogger,
-	}, nil
+	service := api.NewWeatherService(providers...)
+	log.Printf("Weather providers: %s", strings.Join(service.Providers(), ", "))
+	return service
 }
 
-func (b *Bot) Start() {
-	log.Println("Bot started and listening for updates...")
+// Start получает обновления в режиме из конфига и блокируется до вызова Stop
+func (b *Bot) Start() error {
+	defer close(b.loopDone)
 
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
+	updates, err := b.receiveUpdates()
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Bot started and listening for updates (%s)...", b.config.BotMode)
 
-	updates := b.api.GetUpdatesChan(u)
+	b.background.Add(2)
+	go b.runScheduler()
+	go b.runAlertPoller()
+
+	for {
+		select {
+		case <-b.stopping:
+			b.drainWebhook(updates)
+			return nil
+		case update, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			b.submit(update)
+		}
+	}
+}
 
+// drainWebhook передает в обработку обновления, которые вебхук уже принял:
+// Telegram получил на них 200 и повторно их не пришлет. Канал закрывается
+// в stopWebhook после остановки HTTP-сервера. Long polling сдвигает offset
+// только для переданных обновлений, поэтому там дочитывать нечего
+func (b *Bot) drainWebhook(updates <-chan tgbotapi.Update) {
+	if b.config.BotMode != config.ModeWebhook {
+		return
+	}
 	for update := range updates {
-		if update.Message != nil {
-			b.handleMessage(update.Message)
+		b.submit(update)
+	}
+}
+
+// Stop перестает принимать обновления (вебхук при этом снимается в Telegram)
+// и ждет, пока обработчики и фоновые задачи закончат работу, но не дольше
+// дедлайна ctx
+func (b *Bot) Stop(ctx context.Context) {
+	b.stopOnce.Do(func() { close(b.stopping) })
+
+	if b.config.BotMode == config.ModeWebhook {
+		b.stopWebhook(ctx)
+	}
+
+	select {
+	case <-b.loopDone:
+	case <-ctx.Done():
+	}
+	b.inline.stop()
+
+	drained := make(chan struct{})
+	go func() {
+		b.dispatcher.Wait()
+		b.background.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		log.Println("All handlers finished")
+	case <-ctx.Done():
+		queued, inFlight, _ := b.dispatcher.Stats()
+		log.Printf("Shutdown deadline exceeded: %d updates in progress, %d queued", inFlight, queued)
+	}
+}
+
+func (b *Bot) receiveUpdates() (<-chan tgbotapi.Update, error) {
+	if b.config.BotMode == config.ModeWebhook {
+		return b.startWebhook()
+	}
+	return b.pollUpdates(), nil
+}
+
+// pollUpdates - цикл long polling. Offset сдвигается только после того,
+// как обновление принято в обработку, поэтому при остановке непринятые
+// обновления Telegram доставит повторно после рестарта.
+func (b *Bot) pollUpdates() <-chan tgbotapi.Update {
+	updates := make(chan tgbotapi.Update)
+
+	go func() {
+		defer close(updates)
+
+		u := tgbotapi.NewUpdate(0)
+		u.Timeout = pollTimeout
+
+		for {
+			select {
+			case <-b.stopping:
+				return
+			default:
+			}
+
+			b.markPoll()
+			batch, err := b.api.GetUpdates(u)
+			if err != nil {
+				log.Printf("Failed to get updates, retrying in 3 seconds: %v", err)
+				select {
+				case <-time.After(3 * time.Second):
+				case <-b.stopping:
+					return
+				}
+				continue
+			}
+
+			for _, update := range batch {
+				if update.UpdateID < u.Offset {
+					continue
+				}
+				select {
+				case updates <- update:
+					u.Offset = update.UpdateID + 1
+				case <-b.stopping:
+					return
+				}
+			}
 		}
+	}()
+
+	return updates
+}
+
+// submit ставит обновление в очередь. Inline-запросы сначала проходят
+// через debounce, чтобы не ходить к API на каждую набранную букву
+func (b *Bot) submit(update tgbotapi.Update) {
+	key := updateKey(update)
+	if update.InlineQuery != nil {
+		b.inline.schedule(key, func() { b.dispatcher.Submit(key, update) })
+		return
 	}
+	b.dispatcher.Submit(key, update)
+}
+
+func (b *Bot) handleUpdate(update tgbotapi.Update) {
+	if b.banned(update) {
+		return
+	}
+
+	defer metrics.ObserveCommand(metricCommand(update), time.Now())
+
+	switch {
+	case update.Message != nil:
+		b.handleMessage(update.Message)
+	case update.CallbackQuery != nil:
+		b.handleCallback(update.CallbackQuery)
+	case update.InlineQuery != nil:
+		b.handleInlineQuery(update.InlineQuery)
+	}
+}
+
+// QueueStats показывает состояние очереди обновлений для админки
+func (b *Bot) QueueStats() (queued, inFlight, capacity int) {
+	return b.dispatcher.Stats()
 }
 
 func (b *Bot) handleMessage(message *tgbotapi.Message) {
@@ -53,11 +298,33 @@ func (b *Bot) handleMessage(message *tgbotapi.Message) {
 	command := message.Command()
 	args := message.CommandArguments()
 
+	b.detectLang(chatID, message.From)
+
+	limited := command
+	if message.Location != nil {
+		limited = "weather"
+	}
+	if limited != "" && !b.allowCommand(chatID, message.From, limited) {
+		return
+	}
+
 	// Логируем команду в админку
 	if command != "" {
 		b.admin.LogCommand(chatID, command, args)
 	}
 
+	// Значение поля из /settings приходит обычным сообщением,
+	// любая команда отменяет ввод
+	if field, waiting := b.takePendingInput(chatID); waiting && command == "" && message.Text != "" {
+		b.handleSettingsInput(chatID, field, message.Text)
+		return
+	}
+
+	if message.Location != nil {
+		b.handleLocation(chatID, message.Location)
+		return
+	}
+
 	switch command {
 	case "start":
 		b.handleStart(chatID)
@@ -65,19 +332,41 @@ func (b *Bot) handleMessage(message *tgbotapi.Message) {
 		b.handleHelp(chatID)
 	case "weather":
 		b.handleWeather(chatID, args)
+	case "forecast":
+		b.handleForecast(chatID, args)
 	case "news":
 		b.handleNews(chatID)
 	case "exchange":
 		b.handleExchange(chatID, args)
+	case "convert":
+		b.handleConvert(chatID, args)
+	case "alert":
+		b.handleAlert(chatID, args)
+	case "alerts":
+		b.handleAlerts(chatID)
+	case "subscribe":
+		b.handleSubscribe(chatID, args)
+	case "unsubscribe":
+		b.handleUnsubscribe(chatID)
+	case "subscription":
+		b.handleSubscription(chatID)
+	case "lang":
+		b.handleLang(chatID, args)
+	case "settings":
+		b.handleSettings(chatID)
 	default:
 		if message.IsCommand() {
-			b.sendMessage(chatID, "Unknown command. Please use /help")
+			b.sendMessage(chatID, i18n.T(b.lang(chatID), "unknown_command"))
 		}
 	}
 }
 
-func (b *Bot) sendMessage(chatId int64, text string) {
-	msg := tgbotapi.NewMessage(chatId, text)
-	msg.ParseMode = "HTML"
-	b.api.Send(msg)
+// renderer - ответы бота собираются в HTML: в нем написан каталог сообщений
+func renderer(lang i18n.Lang) render.Renderer {
+	return render.New(render.HTML, lang)
+}
+
+// sendMessage отправляет сообщение и возвращает его ID, 0 - если отправить не удалось
+func (b *Bot) sendMessage(chatId int64, text string) int {
+	return b.sendMessageWithKeyboard(chatId, text, nil)
 }
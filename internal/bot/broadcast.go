@@ -0,0 +1,47 @@
+package bot
+
+import (
+	"log"
+	"time"
+)
+
+// Telegram разрешает боту около 30 сообщений в секунду в разные чаты,
+// оставляем запас для обычных ответов
+const broadcastInterval = 40 * time.Millisecond
+
+// Broadcast рассылает сообщение из админки в фоне. При остановке бота
+// рассылка прерывается, итог пишется в лог
+func (b *Bot) Broadcast(text string, chatIDs []int64) {
+	select {
+	case <-b.stopping:
+		log.Printf("📢 Broadcast skipped: bot is stopping")
+		return
+	default:
+	}
+
+	b.background.Add(1)
+	go func() {
+		defer b.background.Done()
+
+		ticker := time.NewTicker(broadcastInterval)
+		defer ticker.Stop()
+
+		sent, failed := 0, 0
+		for _, chatID := range chatIDs {
+			select {
+			case <-ticker.C:
+			case <-b.stopping:
+				log.Printf("📢 Broadcast interrupted: %d sent, %d failed, %d left", sent, failed, len(chatIDs)-sent-failed)
+				return
+			}
+
+			if b.sendMessage(chatID, text) == 0 {
+				failed++
+			} else {
+				sent++
+			}
+		}
+
+		log.Printf("📢 Broadcast finished: %d sent, %d failed", sent, failed)
+	}()
+}
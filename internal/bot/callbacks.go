@@ -0,0 +1,268 @@
+package bot
+
+import (
+	"dailybot/internal/api"
+	"dailybot/internal/i18n"
+	"log"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Данные кнопок имеют вид "1|действие|аргумент". Первое поле - версия
+// формата: если формат поменяется, старые кнопки в истории чата будут
+// распознаны как устаревшие, а не выполнят что-то неожиданное.
+const (
+	callbackVersion   = "1"
+	callbackSeparator = "|"
+	// Telegram ограничивает callback_data 64 байтами
+	maxCallbackData = 64
+)
+
+// Короткие коды действий, чтобы уложиться в лимит callback_data
+const (
+	actionWeatherRefresh  = "wr"
+	actionWeatherTomorrow = "wt"
+	actionExchange        = "ex"
+	actionNewsPage        = "np"
+	actionWeatherAt       = "wl"
+	actionSaveLocation    = "sl"
+	actionChoosePlace     = "pc"
+	actionSettings        = "st"
+	actionSettingsSet     = "ss"
+)
+
+type callbackHandler func(b *Bot, query *tgbotapi.CallbackQuery, args []string) (notice string)
+
+var callbackRoutes = map[string]callbackHandler{
+	actionWeatherRefresh:  (*Bot).callbackWeatherRefresh,
+	actionWeatherTomorrow: (*Bot).callbackWeatherTomorrow,
+	actionExchange:        (*Bot).callbackExchange,
+	actionNewsPage:        (*Bot).callbackNewsPage,
+	actionWeatherAt:       (*Bot).callbackWeatherAt,
+	actionSaveLocation:    (*Bot).callbackSaveLocation,
+	actionChoosePlace:     (*Bot).callbackChoosePlace,
+	actionSettings:        (*Bot).callbackSettings,
+	actionSettingsSet:     (*Bot).callbackSettingsSet,
+}
+
+var quickCurrencies = []string{"USD", "EUR", "CNY"}
+
+// callbackData собирает данные кнопки. Пустая строка - данные не влезли в лимит
+func callbackData(action string, args ...string) string {
+	data := strings.Join(append([]string{callbackVersion, action}, args...), callbackSeparator)
+	if len(data) > maxCallbackData {
+		return ""
+	}
+	return data
+}
+
+func parseCallbackData(data string) (action string, args []string, ok bool) {
+	parts := strings.Split(data, callbackSeparator)
+	if len(parts) < 2 || parts[0] != callbackVersion {
+		return "", nil, false
+	}
+	return parts[1], parts[2:], true
+}
+
+func (b *Bot) handleCallback(query *tgbotapi.CallbackQuery) {
+	var notice string
+
+	action, args, ok := parseCallbackData(query.Data)
+	if handler, exists := callbackRoutes[action]; ok && exists && query.Message != nil {
+		b.detectLang(query.Message.Chat.ID, query.From)
+		lang := b.lang(query.Message.Chat.ID)
+
+		// Отказ показываем всплывающим уведомлением на каждое нажатие
+		if decision := b.limiter.Allow(query.From.ID, callbackCommand(action)); !decision.Allowed {
+			notice = i18n.T(lang, "ratelimit.user", i18n.Duration(decision.RetryAfter))
+		} else {
+			notice = handler(b, query, args)
+		}
+	} else {
+		notice = i18n.T(b.lang(query.From.ID), "callback.outdated")
+	}
+
+	if _, err := b.api.Request(tgbotapi.NewCallback(query.ID, notice)); err != nil {
+		log.Printf("Failed to answer callback: %v", err)
+	}
+}
+
+func (b *Bot) callbackWeatherRefresh(query *tgbotapi.CallbackQuery, args []string) string {
+	lang := b.lang(query.Message.Chat.ID)
+	if len(args) != 1 {
+		return i18n.T(lang, "callback.outdated")
+	}
+	city := args[0]
+	b.admin.LogCommand(query.Message.Chat.ID, "weather", city)
+
+	weather, err := b.cityWeather(query.Message.Chat.ID, city, lang, b.units(query.Message.Chat.ID))
+	if err != nil {
+		return i18n.ErrorText(lang, err)
+	}
+
+	b.editMessage(query.Message.Chat.ID, query.Message.MessageID, weather.text, weather.keyboard)
+	return ""
+}
+
+func (b *Bot) callbackWeatherTomorrow(query *tgbotapi.CallbackQuery, args []string) string {
+	lang := b.lang(query.Message.Chat.ID)
+	if len(args) != 1 {
+		return i18n.T(lang, "callback.outdated")
+	}
+	city := args[0]
+	b.admin.LogCommand(query.Message.Chat.ID, "forecast", city)
+
+	place, err := b.resolvePlace(query.Message.Chat.ID, city, lang)
+	if err != nil {
+		return i18n.ErrorText(lang, err)
+	}
+
+	forecast, err := b.weather.TomorrowForecast(place, lang, b.units(query.Message.Chat.ID))
+	if err != nil {
+		return i18n.ErrorText(lang, err)
+	}
+
+	keyboard := inlineKeyboard(inlineButton(i18n.T(lang, "button.current_weather"), actionWeatherRefresh, city))
+	b.editMessage(query.Message.Chat.ID, query.Message.MessageID, renderer(lang).Forecast(forecast), keyboard)
+	return ""
+}
+
+func (b *Bot) callbackExchange(query *tgbotapi.CallbackQuery, args []string) string {
+	lang := b.lang(query.Message.Chat.ID)
+	if len(args) != 1 {
+		return i18n.T(lang, "callback.outdated")
+	}
+	currency := args[0]
+	b.admin.LogCommand(query.Message.Chat.ID, "exchange", currency)
+
+	rateInfo, err := exchangeRate(currency, lang)
+	if err != nil {
+		return i18n.ErrorText(lang, err)
+	}
+
+	b.editMessage(query.Message.Chat.ID, query.Message.MessageID, rateInfo, currencyKeyboard(b.favoriteCurrencies(query.Message.Chat.ID)))
+	return ""
+}
+
+func (b *Bot) callbackNewsPage(query *tgbotapi.CallbackQuery, args []string) string {
+	lang := b.lang(query.Message.Chat.ID)
+	if len(args) != 1 {
+		return i18n.T(lang, "callback.outdated")
+	}
+	page, err := strconv.Atoi(args[0])
+	if err != nil {
+		return i18n.T(lang, "callback.outdated")
+	}
+	b.admin.LogCommand(query.Message.Chat.ID, "news", args[0])
+
+	news, err := api.GetNewsPage(b.config.NewsAPIKey, page, lang)
+	if err != nil {
+		return i18n.ErrorText(lang, err)
+	}
+
+	b.editMessage(query.Message.Chat.ID, query.Message.MessageID, renderer(lang).News(news), newsKeyboard(page, news.HasMore, lang))
+	return ""
+}
+
+func inlineButton(text, action string, args ...string) *tgbotapi.InlineKeyboardButton {
+	data := callbackData(action, args...)
+	if data == "" {
+		return nil
+	}
+	button := tgbotapi.NewInlineKeyboardButtonData(text, data)
+	return &button
+}
+
+// inlineKeyboard собирает клавиатуру в один ряд, пропуская кнопки,
+// данные которых не влезли в лимит. Без кнопок возвращает nil.
+func inlineKeyboard(buttons ...*tgbotapi.InlineKeyboardButton) *tgbotapi.InlineKeyboardMarkup {
+	return inlineRows(buttons)
+}
+
+// inlineRows - то же для клавиатуры из нескольких рядов. Пустые ряды пропускаются
+func inlineRows(rows ...[]*tgbotapi.InlineKeyboardButton) *tgbotapi.InlineKeyboardMarkup {
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for _, buttons := range rows {
+		var row []tgbotapi.InlineKeyboardButton
+		for _, button := range buttons {
+			if button != nil {
+				row = append(row, *button)
+			}
+		}
+		if len(row) > 0 {
+			keyboard = append(keyboard, row)
+		}
+	}
+	if len(keyboard) == 0 {
+		return nil
+	}
+
+	markup := tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+	return &markup
+}
+
+func weatherKeyboard(city string, lang i18n.Lang) *tgbotapi.InlineKeyboardMarkup {
+	return inlineKeyboard(
+		inlineButton(i18n.T(lang, "button.refresh"), actionWeatherRefresh, city),
+		inlineButton(i18n.T(lang, "button.tomorrow"), actionWeatherTomorrow, city),
+	)
+}
+
+// currencyKeyboard - кнопки курсов, не больше пяти в ряду
+func currencyKeyboard(codes []string) *tgbotapi.InlineKeyboardMarkup {
+	var rows [][]*tgbotapi.InlineKeyboardButton
+	for i, code := range codes {
+		if i%5 == 0 {
+			rows = append(rows, nil)
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], inlineButton(code, actionExchange, code))
+	}
+	return inlineRows(rows...)
+}
+
+func newsKeyboard(page int, hasMore bool, lang i18n.Lang) *tgbotapi.InlineKeyboardMarkup {
+	var prev, next *tgbotapi.InlineKeyboardButton
+	if page > 1 {
+		prev = inlineButton(i18n.T(lang, "button.back"), actionNewsPage, strconv.Itoa(page-1))
+	}
+	if hasMore {
+		next = inlineButton(i18n.T(lang, "button.more_news"), actionNewsPage, strconv.Itoa(page+1))
+	}
+	return inlineKeyboard(prev, next)
+}
+
+// editMessage заменяет текст и кнопки сообщения. Ошибку "message is not modified"
+// (пользователь обновил, а данные не изменились) не считаем проблемой.
+func (b *Bot) editMessage(chatID int64, messageID int, text string, keyboard *tgbotapi.InlineKeyboardMarkup) error {
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	edit.ParseMode = "HTML"
+	edit.ReplyMarkup = keyboard
+
+	if _, err := b.api.Send(edit); err != nil && !strings.Contains(err.Error(), "message is not modified") {
+		log.Printf("Failed to edit message %d in chat %d: %v", messageID, chatID, err)
+		return err
+	}
+	return nil
+}
+
+func (b *Bot) sendMessageWithKeyboard(chatID int64, text string, keyboard *tgbotapi.InlineKeyboardMarkup) int {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+
+	sent, err := b.api.Send(msg)
+	if err != nil {
+		log.Printf("Failed to send message to chat %d: %v", chatID, err)
+		return 0
+	}
+	return sent.MessageID
+}
+
+// exchangePrompt - ответ на /exchange без аргументов
+func exchangePrompt(lang i18n.Lang) string {
+	return i18n.T(lang, "exchange.prompt", strings.Join(quickCurrencies, ", "))
+}
@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"sync"
+	"sync/atomic"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// dispatcher обрабатывает обновления разных чатов параллельно,
+// сохраняя порядок сообщений внутри одного чата. Для каждого чата
+// с непустой очередью работает своя горутина, а семафор workers
+// ограничивает, сколько из них обрабатывают обновления одновременно.
+type dispatcher struct {
+	handle func(tgbotapi.Update)
+
+	slots   chan struct{} // ограничивает общее число ожидающих и обрабатываемых обновлений
+	workers chan struct{} // ограничивает параллельность
+
+	mu    sync.Mutex
+	chats map[int64][]tgbotapi.Update
+
+	wg       sync.WaitGroup
+	inFlight atomic.Int64
+}
+
+func newDispatcher(workers, queueSize int, handle func(tgbotapi.Update)) *dispatcher {
+	return &dispatcher{
+		handle:  handle,
+		slots:   make(chan struct{}, queueSize),
+		workers: make(chan struct{}, workers),
+		chats:   make(map[int64][]tgbotapi.Update),
+	}
+}
+
+// Submit ставит обновление в очередь чата. Если очередь заполнена,
+// блокируется - так прием обновлений притормаживает вместе с обработкой.
+func (d *dispatcher) Submit(key int64, update tgbotapi.Update) {
+	d.slots <- struct{}{}
+
+	d.mu.Lock()
+	pending, running := d.chats[key]
+	d.chats[key] = append(pending, update)
+	if !running {
+		d.wg.Add(1)
+	}
+	d.mu.Unlock()
+
+	if !running {
+		go d.run(key)
+	}
+}
+
+func (d *dispatcher) run(key int64) {
+	defer d.wg.Done()
+
+	for {
+		d.mu.Lock()
+		pending := d.chats[key]
+		if len(pending) == 0 {
+			delete(d.chats, key)
+			d.mu.Unlock()
+			return
+		}
+		update := pending[0]
+		d.chats[key] = pending[1:]
+		d.mu.Unlock()
+
+		d.workers <- struct{}{}
+		d.inFlight.Add(1)
+		d.handle(update)
+		d.inFlight.Add(-1)
+		<-d.workers
+
+		<-d.slots
+	}
+}
+
+// Wait дожидается обработки всех принятых обновлений
+func (d *dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Stats возвращает число ожидающих и обрабатываемых обновлений и размер очереди
+func (d *dispatcher) Stats() (queued, inFlight, capacity int) {
+	active := int(d.inFlight.Load())
+	queued = len(d.slots) - active
+	if queued < 0 {
+		queued = 0
+	}
+	return queued, active, cap(d.slots)
+}
+
+// updateKey определяет чат, в рамках которого нужно сохранить порядок
+func updateKey(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil:
+		return update.Message.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return update.CallbackQuery.Message.Chat.ID
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.From.ID
+	case update.InlineQuery != nil:
+		return update.InlineQuery.From.ID
+	}
+	return 0
+}
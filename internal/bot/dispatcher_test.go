@@ -0,0 +1,158 @@
+package bot
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const testTimeout = 5 * time.Second
+
+func TestDispatcherKeepsOrderWithinChat(t *testing.T) {
+	var mu sync.Mutex
+	var got []int
+
+	d := newDispatcher(4, 10, func(update tgbotapi.Update) {
+		// Неравномерная обработка не должна менять порядок
+		if update.UpdateID%3 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		mu.Lock()
+		got = append(got, update.UpdateID)
+		mu.Unlock()
+	})
+
+	const n = 50
+	for i := 0; i < n; i++ {
+		d.Submit(1, tgbotapi.Update{UpdateID: i})
+	}
+	d.Wait()
+
+	if len(got) != n {
+		t.Fatalf("handled %d updates, want %d", len(got), n)
+	}
+	for i, id := range got {
+		if id != i {
+			t.Fatalf("update %d handled at position %d: %v", id, i, got)
+		}
+	}
+}
+
+func TestDispatcherRunsChatsInParallel(t *testing.T) {
+	started := make(chan int, 2)
+	release := make(chan struct{})
+
+	d := newDispatcher(2, 10, func(update tgbotapi.Update) {
+		started <- update.UpdateID
+		<-release
+	})
+
+	d.Submit(1, tgbotapi.Update{UpdateID: 1})
+	d.Submit(2, tgbotapi.Update{UpdateID: 2})
+
+	// Оба обработчика должны стартовать, не дожидаясь друг друга
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(testTimeout):
+			t.Fatal("updates of different chats are not handled in parallel")
+		}
+	}
+	close(release)
+	d.Wait()
+}
+
+func TestDispatcherLimitsWorkers(t *testing.T) {
+	const workers = 2
+	var running, peak atomic.Int64
+
+	d := newDispatcher(workers, 20, func(update tgbotapi.Update) {
+		now := running.Add(1)
+		for {
+			old := peak.Load()
+			if now <= old || peak.CompareAndSwap(old, now) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+	})
+
+	for key := int64(1); key <= 10; key++ {
+		d.Submit(key, tgbotapi.Update{UpdateID: int(key)})
+	}
+	d.Wait()
+
+	if got := peak.Load(); got > workers {
+		t.Fatalf("%d handlers ran at once, limit is %d", got, workers)
+	}
+}
+
+func TestDispatcherBlocksWhenQueueIsFull(t *testing.T) {
+	release := make(chan struct{})
+	handling := make(chan struct{}, 3)
+
+	d := newDispatcher(1, 2, func(update tgbotapi.Update) {
+		handling <- struct{}{}
+		<-release
+	})
+
+	d.Submit(1, tgbotapi.Update{UpdateID: 1})
+	d.Submit(1, tgbotapi.Update{UpdateID: 2})
+
+	select {
+	case <-handling:
+	case <-time.After(testTimeout):
+		t.Fatal("first update is not handled")
+	}
+
+	if queued, inFlight, capacity := d.Stats(); queued != 1 || inFlight != 1 || capacity != 2 {
+		t.Fatalf("Stats() = %d, %d, %d, want 1, 1, 2", queued, inFlight, capacity)
+	}
+
+	submitted := make(chan struct{})
+	go func() {
+		d.Submit(2, tgbotapi.Update{UpdateID: 3})
+		close(submitted)
+	}()
+
+	select {
+	case <-submitted:
+		t.Fatal("Submit did not block on a full queue")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+
+	select {
+	case <-submitted:
+	case <-time.After(testTimeout):
+		t.Fatal("Submit is still blocked after the queue drained")
+	}
+	d.Wait()
+}
+
+func TestDispatcherWaitAfterSubmit(t *testing.T) {
+	var handled atomic.Int64
+
+	d := newDispatcher(3, 100, func(update tgbotapi.Update) {
+		time.Sleep(2 * time.Millisecond)
+		handled.Add(1)
+	})
+
+	const n = 30
+	for i := 0; i < n; i++ {
+		d.Submit(int64(i%5), tgbotapi.Update{UpdateID: i})
+	}
+	d.Wait()
+
+	if got := handled.Load(); got != n {
+		t.Fatalf("Wait returned after %d of %d updates", got, n)
+	}
+	if queued, inFlight, _ := d.Stats(); queued != 0 || inFlight != 0 {
+		t.Fatalf("Stats() after Wait = %d queued, %d in flight", queued, inFlight)
+	}
+}
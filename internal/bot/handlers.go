@@ -2,89 +2,235 @@ package bot
 
 import (
 	"dailybot/internal/api"
+	"dailybot/internal/i18n"
+	"dailybot/internal/storage"
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 func (b *Bot) handleStart(chatID int64) {
-	text := `<b>Привет! Я ДейлиБот - твой помощник на каждый день!</b>
-
-<b>Мои команды:</b>
-/weather [город] - прогноз погоды
-/exchange [валюта] - курс валют ЦБ РФ
-/news - главные новости дня
-/help - подробная справка`
-
-	b.sendMessage(chatID, text)
+	b.sendLocationRequest(chatID, i18n.T(b.lang(chatID), "start"))
 }
 
 func (b *Bot) handleHelp(chatID int64) {
-	text := `<b>Справка по командам:</b>
-
-<b>Мои команды:</b>
-<b>/weather [город]</b> - получить прогноз погоды
-Пример: <code>/weather Москва</code>
-
-<b>/news</b> - топ-5 главных новостей дня
-Актуальные новости из российских источников
-
-<b>/exchange [валюта]</b> - курс валют по данным ЦБ РФ
-Пример: <code>/exchange USD</code> или <code>/exchange EUR</code>
-
-<i>Бот работает на языке Go и использует официальные API</i>`
-
-	b.sendMessage(chatID, text)
+	b.sendMessage(chatID, i18n.T(b.lang(chatID), "help"))
 }
 
 func (b *Bot) handleWeather(chatID int64, args string) {
+	lang := b.lang(chatID)
+
+	// Без аргументов - место из профиля: координаты, если сохранена геопозиция, иначе город
 	city := strings.TrimSpace(args)
 	if city == "" {
-		b.sendMessage(chatID, "Укажите город для получения прогноза погоды\n\nПример: <code>/weather Москва</code>")
+		profile := b.profile(chatID)
+		if profile.Location != nil {
+			b.respondWithWeatherAt(chatID, *profile.Location, lang)
+			return
+		}
+		city = profile.City
+	}
+	if city == "" {
+		b.sendLocationRequest(chatID, i18n.T(lang, "weather.usage"))
 		return
 	}
 
-	b.sendMessage(chatID, "Получаю данные о погоде...")
+	units := b.units(chatID)
+	b.respondWithProgress(chatID, i18n.T(lang, "weather.loading"), func() (reply, error) {
+		return b.cityWeather(chatID, city, lang, units)
+	})
+}
 
-	weatherInfo, err := api.GetWeather(city, b.config.OpenWeatherKey)
-	if err != nil {
-		b.sendMessage(chatID, fmt.Sprintf("<b>Ошибка:</b> %s", err.Error()))
+func (b *Bot) handleForecast(chatID int64, args string) {
+	lang := b.lang(chatID)
+	fields := strings.Fields(args)
+	days := 3
+	profile := b.profile(chatID)
+	defaultCity := profile.City
+
+	// Последний аргумент - количество дней, если это число. Одно число без
+	// города из профиля считаем городом, только если дней столько не бывает:
+	// "/forecast 3" - это дни, и нужно попросить указать город
+	if len(fields) > 0 {
+		if n, err := strconv.Atoi(fields[len(fields)-1]); err == nil {
+			if len(fields) > 1 || defaultCity != "" || (n >= 1 && n <= api.MaxForecastDays) {
+				days = n
+				fields = fields[:len(fields)-1]
+			}
+		}
+	}
+
+	// Место из профиля по сохраненной геопозиции ищем по координатам
+	city := strings.Join(fields, " ")
+	var saved *storage.Location
+	if city == "" {
+		city = defaultCity
+		saved = profile.Location
+	}
+	if city == "" {
+		b.sendMessage(chatID, i18n.T(lang, "forecast.usage"))
 		return
 	}
 
-	b.sendMessage(chatID, weatherInfo)
+	units := b.units(chatID)
+	b.respondWithProgress(chatID, i18n.T(lang, "forecast.loading"), func() (reply, error) {
+		var place api.Place
+		if saved != nil {
+			place = savedPlace(city, *saved)
+		} else {
+			var err error
+			if place, err = b.resolvePlace(chatID, city, lang); err != nil {
+				return reply{}, err
+			}
+		}
+		forecast, err := b.weather.Forecast(place, days, lang, units)
+		if err != nil {
+			return reply{}, err
+		}
+		return reply{text: renderer(lang).Forecast(forecast)}, nil
+	})
 }
 
 func (b *Bot) handleNews(chatID int64) {
-	b.sendMessage(chatID, "Загружаю актуальные новости...")
-
+	lang := b.lang(chatID)
 	log.Printf("Fetching news for chat %d", chatID)
 
-	newsInfo, err := api.GetNews(b.config.NewsAPIKey)
-	if err != nil {
-		log.Printf("News error: %v", err)
-		b.sendMessage(chatID, fmt.Sprintf("<b>Ошибка:</b> %s", err.Error()))
+	b.respondWithProgress(chatID, i18n.T(lang, "news.loading"), func() (reply, error) {
+		news, err := api.GetNewsPage(b.config.NewsAPIKey, 1, lang)
+		if err != nil {
+			log.Printf("News error: %v", err)
+			return reply{}, err
+		}
+
+		log.Printf("News fetched successfully")
+		return reply{text: renderer(lang).News(news), keyboard: newsKeyboard(1, news.HasMore, lang)}, nil
+	})
+}
+
+func (b *Bot) handleExchange(chatID int64, args string) {
+	lang := b.lang(chatID)
+
+	fields := strings.Fields(strings.ToUpper(args))
+	if len(fields) == 0 {
+		b.handleFavoriteRates(chatID)
 		return
 	}
+	currency := fields[0]
+
+	var fetch func() (string, error)
+	switch {
+	case len(fields) == 1:
+		fetch = func() (string, error) { return exchangeRate(currency, lang) }
+	case historyPeriodRe.MatchString(fields[1]):
+		days, _ := strconv.Atoi(strings.TrimRight(fields[1], "DД"))
+		fetch = func() (string, error) {
+			history, err := api.GetExchangeRateHistory(currency, days)
+			if err != nil {
+				return "", err
+			}
+			return renderer(lang).RateHistory(history), nil
+		}
+	default:
+		date, err := parseRateDate(fields[1])
+		if err != nil {
+			b.sendMessage(chatID, i18n.T(lang, "error", i18n.T(lang, "exchange.err.date_format")))
+			return
+		}
+		fetch = func() (string, error) {
+			rate, err := api.GetExchangeRateOn(currency, date)
+			if err != nil {
+				return "", err
+			}
+			return renderer(lang).RateOn(rate), nil
+		}
+	}
 
-	log.Printf("News fetched successfully")
-	b.sendMessage(chatID, newsInfo)
+	b.respondWithProgress(chatID, i18n.T(lang, "exchange.loading"), func() (reply, error) {
+		rateInfo, err := fetch()
+		return reply{text: rateInfo}, err
+	})
 }
 
-func (b *Bot) handleExchange(chatID int64, args string) {
-	currency := strings.TrimSpace(strings.ToUpper(args))
-	if currency == "" {
-		b.sendMessage(chatID, "Укажите код валюты для получения курса\n\nПример: <code>/exchange USD</code>\n\nДоступно: USD, EUR, CNY, GBP, JPY и другие")
+// handleFavoriteRates - /exchange без аргументов: курсы избранных валют
+// из профиля, а если их нет - подсказка с кнопками популярных
+func (b *Bot) handleFavoriteRates(chatID int64) {
+	lang := b.lang(chatID)
+
+	favorites := b.profile(chatID).Currencies
+	if len(favorites) == 0 {
+		b.sendMessageWithKeyboard(chatID, exchangePrompt(lang), currencyKeyboard(quickCurrencies))
+		return
+	}
+
+	b.respondWithProgress(chatID, i18n.T(lang, "exchange.loading"), func() (reply, error) {
+		rates := make([]string, 0, len(favorites))
+		for _, code := range favorites {
+			rateInfo, err := exchangeRate(code, lang)
+			if err != nil {
+				return reply{}, err
+			}
+			rates = append(rates, rateInfo)
+		}
+		return reply{text: strings.Join(rates, "\n\n"), keyboard: currencyKeyboard(favorites)}, nil
+	})
+}
+
+// exchangeRate - текущий курс валюты. На неизвестный код отвечаем
+// не ошибкой, а списком доступных валют
+func exchangeRate(code string, lang i18n.Lang) (string, error) {
+	quote, err := api.GetExchangeRate(code)
+	var unknown *api.UnknownCurrencyError
+	if errors.As(err, &unknown) {
+		return renderer(lang).UnknownCurrency(unknown), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return renderer(lang).Rate(quote), nil
+}
+
+var historyPeriodRe = regexp.MustCompile(`^\d+[DД]$`)
+
+func parseRateDate(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", "02.01.2006"} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("неверный формат даты %q", value)
+}
+
+func (b *Bot) handleConvert(chatID int64, args string) {
+	lang := b.lang(chatID)
+	usage := i18n.T(lang, "convert.usage")
+
+	fields := strings.Fields(args)
+	// Разрешаем запись "150 USD в EUR" и "150 USD to EUR"
+	if len(fields) == 4 && (strings.EqualFold(fields[2], "в") || strings.EqualFold(fields[2], "to")) {
+		fields = append(fields[:2], fields[3])
+	}
+	if len(fields) != 3 {
+		b.sendMessage(chatID, usage)
 		return
 	}
 
-	b.sendMessage(chatID, "Получаю актуальный курс валют...")
+	// ParseFloat принимает и "inf", и "nan" - такие суммы тоже не числа
+	amount, err := strconv.ParseFloat(strings.ReplaceAll(fields[0], ",", "."), 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		b.sendMessage(chatID, i18n.T(lang, "error", i18n.T(lang, "convert.err.not_number"))+"\n\n"+usage)
+		return
+	}
 
-	rateInfo, err := api.GetExchangeRate(currency)
+	result, err := api.ConvertCurrency(amount, fields[1], fields[2])
 	if err != nil {
-		b.sendMessage(chatID, fmt.Sprintf("<b>Ошибка:</b> %s", err.Error()))
+		b.sendMessage(chatID, i18n.T(lang, "error", i18n.ErrorText(lang, err)))
 		return
 	}
 
-	b.sendMessage(chatID, rateInfo)
+	b.sendMessage(chatID, renderer(lang).Conversion(result))
 }
@@ -0,0 +1,51 @@
+package bot
+
+import (
+	"context"
+	"dailybot/internal/config"
+	"time"
+)
+
+// Long polling ждет обновлений до pollTimeout секунд. Если цикл не начинал
+// новый запрос дольше pollStaleAfter, он завис
+const (
+	pollTimeout    = 60
+	pollStaleAfter = 2 * pollTimeout * time.Second
+)
+
+// markPoll отмечает, что цикл получения обновлений жив
+func (b *Bot) markPoll() {
+	b.lastPoll.Store(time.Now().UnixNano())
+}
+
+// PollStatus - когда цикл последний раз обращался к Telegram (в режиме
+// вебхука - когда пришло последнее обновление) и не завис ли он.
+// Нулевое время - обращений еще не было
+func (b *Bot) PollStatus() (last time.Time, stale bool) {
+	if nanos := b.lastPoll.Load(); nanos != 0 {
+		last = time.Unix(0, nanos)
+	}
+
+	// Вебхук ждет обновлений от Telegram, тишина в нем - не зависание
+	if b.config.BotMode != config.ModePolling {
+		return last, false
+	}
+	return last, last.IsZero() || time.Since(last) > pollStaleAfter
+}
+
+// PingTelegram проверяет, что Bot API доступен и токен действует (getMe)
+func (b *Bot) PingTelegram(ctx context.Context) error {
+	// У библиотеки нет запросов с контекстом, поэтому ждем ответа не дольше ctx
+	done := make(chan error, 1)
+	go func() {
+		_, err := b.api.GetMe()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,208 @@
+package bot
+
+import (
+	"dailybot/internal/api"
+	"dailybot/internal/i18n"
+	"dailybot/internal/render"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	// Пока пользователь набирает запрос, Telegram присылает его на каждую букву.
+	// Отвечаем только на запрос, после которого пауза была дольше inlineDebounce
+	inlineDebounce = 600 * time.Millisecond
+	minInlineCity  = 2 // короче - скорее всего, город еще набирается
+
+	// Сколько Telegram может кешировать ответ на одинаковый запрос
+	inlineCacheWeather  = 600
+	inlineCacheExchange = 1800
+	inlineCacheNews     = 900
+	inlineCacheEmpty    = 10
+)
+
+// inlineDebouncer откладывает обработку inline-запроса и отменяет ее,
+// если от того же пользователя пришел более новый запрос
+type inlineDebouncer struct {
+	mu      sync.Mutex
+	pending map[int64]*time.Timer
+	stopped bool
+	// running - сработавшие таймеры, которые еще передают запрос дальше
+	running sync.WaitGroup
+}
+
+func newInlineDebouncer() *inlineDebouncer {
+	return &inlineDebouncer{pending: make(map[int64]*time.Timer)}
+}
+
+func (d *inlineDebouncer) schedule(userID int64, run func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.stopped {
+		return
+	}
+	if previous, ok := d.pending[userID]; ok {
+		previous.Stop()
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(inlineDebounce, func() {
+		d.mu.Lock()
+		if d.stopped || d.pending[userID] != timer {
+			d.mu.Unlock()
+			return
+		}
+		delete(d.pending, userID)
+		d.running.Add(1)
+		d.mu.Unlock()
+
+		// run может ждать места в очереди диспетчера - без блокировки,
+		// чтобы не задерживать остальные запросы
+		defer d.running.Done()
+		run()
+	})
+	d.pending[userID] = timer
+}
+
+// stop отменяет отложенные запросы, новые больше не принимаются. Возвращается,
+// когда уже сработавшие запросы переданы дальше
+func (d *inlineDebouncer) stop() {
+	d.mu.Lock()
+	d.stopped = true
+	for userID, timer := range d.pending {
+		timer.Stop()
+		delete(d.pending, userID)
+	}
+	d.mu.Unlock()
+
+	d.running.Wait()
+}
+
+// inlineResult - карточка для ответа на inline-запрос
+type inlineResult struct {
+	title string
+	text  string
+}
+
+func (b *Bot) handleInlineQuery(query *tgbotapi.InlineQuery) {
+	b.detectLang(query.From.ID, query.From)
+	lang := b.lang(query.From.ID)
+
+	// Без ответа Telegram просто не покажет подсказки
+	if !b.limiter.Allow(query.From.ID, "inline").Allowed {
+		return
+	}
+
+	results, cacheTime := b.buildInlineResults(strings.TrimSpace(query.Query), query.From.ID, lang)
+
+	articles := make([]interface{}, 0, len(results))
+	for _, result := range results {
+		article := tgbotapi.NewInlineQueryResultArticleHTML(inlineResultID(result.text), result.title, result.text)
+		article.Description = previewText(result.text)
+		articles = append(articles, article)
+	}
+
+	answer := tgbotapi.InlineConfig{
+		InlineQueryID: query.ID,
+		Results:       articles,
+		CacheTime:     cacheTime,
+		// Ответ зависит от языка пользователя
+		IsPersonal: true,
+	}
+	if _, err := b.api.Request(answer); err != nil {
+		log.Printf("Failed to answer inline query: %v", err)
+	}
+}
+
+// buildInlineResults разбирает запрос: "weather Казань" или "погода Казань",
+// код валюты ("usd"), "news" или "новости". Прочий текст считаем городом.
+// Пустой запрос показывает курсы избранных валют пользователя.
+func (b *Bot) buildInlineResults(query string, userID int64, lang i18n.Lang) ([]inlineResult, int) {
+	units := b.units(userID)
+
+	command, rest, _ := strings.Cut(query, " ")
+	rest = strings.TrimSpace(rest)
+
+	switch {
+	case query == "":
+		var results []inlineResult
+		for _, code := range b.favoriteCurrencies(userID) {
+			if result, ok := b.inlineExchange(code, lang); ok {
+				results = append(results, result)
+			}
+		}
+		return results, inlineCacheExchange
+
+	case strings.EqualFold(command, "weather") || strings.EqualFold(command, "погода"):
+		return b.inlineWeather(userID, rest, lang, units)
+
+	case strings.EqualFold(query, "news") || strings.EqualFold(query, "новости"):
+		news, err := api.GetNews(b.config.NewsAPIKey, lang)
+		if err != nil {
+			return nil, inlineCacheEmpty
+		}
+		return []inlineResult{{title: i18n.T(lang, "inline.news_title"), text: renderer(lang).News(news)}}, inlineCacheNews
+
+	case currencyCodeRe.MatchString(query):
+		result, ok := b.inlineExchange(strings.ToUpper(query), lang)
+		if !ok {
+			return nil, inlineCacheEmpty
+		}
+		return []inlineResult{result}, inlineCacheExchange
+	}
+
+	return b.inlineWeather(userID, query, lang, units)
+}
+
+func (b *Bot) inlineWeather(userID int64, city string, lang i18n.Lang, units api.Units) ([]inlineResult, int) {
+	if utf8.RuneCountInString(city) < minInlineCity {
+		return nil, inlineCacheEmpty
+	}
+
+	text, err := b.placeWeather(userID, city, lang, units)
+	if err != nil {
+		return nil, inlineCacheEmpty
+	}
+
+	return []inlineResult{{title: i18n.T(lang, "inline.weather_title", city), text: text}}, inlineCacheWeather
+}
+
+func (b *Bot) inlineExchange(code string, lang i18n.Lang) (inlineResult, bool) {
+	quote, err := api.GetExchangeRate(code)
+	if err != nil {
+		return inlineResult{}, false
+	}
+
+	return inlineResult{title: i18n.T(lang, "inline.exchange_title", code), text: renderer(lang).Rate(quote)}, true
+}
+
+// inlineResultID - стабильный короткий ID карточки (Telegram допускает до 64 байт)
+func inlineResultID(text string) string {
+	h := fnv.New64a()
+	h.Write([]byte(text))
+	return fmt.Sprintf("%x", h.Sum64())
+}
+
+// previewText собирает описание карточки из первых строк ответа без разметки
+func previewText(text string) string {
+	plain := render.Convert(text, render.Plain)
+
+	var lines []string
+	for _, line := range strings.Split(plain, "\n")[1:] {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+		if len(lines) == 2 {
+			break
+		}
+	}
+	return strings.Join(lines, ", ")
+}
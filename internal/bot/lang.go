@@ -0,0 +1,90 @@
+package bot
+
+import (
+	"dailybot/internal/i18n"
+	"dailybot/internal/storage"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Язык из Telegram забываем, если чат долго не писал: при следующем
+// сообщении он определится заново
+const detectedLangTTL = 24 * time.Hour
+
+// detectedLang - язык из настроек Telegram и когда чат последний раз писал
+type detectedLang struct {
+	lang     i18n.Lang
+	lastSeen time.Time
+}
+
+// lang возвращает язык чата: выбранный в профиле, а если его нет -
+// определенный по настройкам Telegram
+func (b *Bot) lang(chatID int64) i18n.Lang {
+	if lang, ok := i18n.Parse(b.profile(chatID).Lang); ok {
+		return lang
+	}
+
+	b.profilesMu.RLock()
+	defer b.profilesMu.RUnlock()
+
+	if detected, ok := b.detectedLangs[chatID]; ok {
+		return detected.lang
+	}
+	return i18n.Default
+}
+
+// detectLang запоминает язык из настроек Telegram пользователя. В профиль
+// такой язык не пишем: после рестарта он определится заново по первому сообщению
+func (b *Bot) detectLang(chatID int64, from *tgbotapi.User) {
+	if from == nil {
+		return
+	}
+	now := time.Now()
+
+	b.profilesMu.Lock()
+	defer b.profilesMu.Unlock()
+
+	// Старые записи убираем не чаще раза в час, чтобы не обходить карту на каждое сообщение
+	if now.Sub(b.langsPrunedAt) > time.Hour {
+		for id, detected := range b.detectedLangs {
+			if now.Sub(detected.lastSeen) > detectedLangTTL {
+				delete(b.detectedLangs, id)
+			}
+		}
+		b.langsPrunedAt = now
+	}
+
+	detected, ok := b.detectedLangs[chatID]
+	if !ok {
+		detected.lang = i18n.FromTelegram(from.LanguageCode)
+	}
+	detected.lastSeen = now
+	b.detectedLangs[chatID] = detected
+}
+
+func (b *Bot) handleLang(chatID int64, args string) {
+	code := strings.TrimSpace(args)
+	if code == "" {
+		lang := b.lang(chatID)
+		b.sendMessage(chatID, i18n.T(lang, "lang.current", i18n.T(lang, "lang.name")))
+		return
+	}
+
+	lang, ok := i18n.Parse(code)
+	if !ok {
+		b.sendMessage(chatID, i18n.T(b.lang(chatID), "lang.unknown"))
+		return
+	}
+
+	if _, err := b.updateProfile(chatID, func(p *storage.Profile) { p.Lang = string(lang) }); err != nil {
+		log.Printf("Failed to save language for chat %d: %v", chatID, err)
+		current := b.lang(chatID)
+		b.sendMessage(chatID, i18n.T(current, "error", i18n.T(current, "lang.err.save")))
+		return
+	}
+
+	b.sendMessage(chatID, i18n.T(lang, "lang.changed"))
+}
@@ -0,0 +1,99 @@
+package bot
+
+import (
+	"context"
+	"dailybot/internal/i18n"
+	"dailybot/internal/ratelimit"
+	"dailybot/internal/storage"
+	"log"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Какой команде соответствует кнопка: нажатия считаются в лимит команды
+var callbackCommands = map[string]string{
+	actionWeatherRefresh:  "weather",
+	actionWeatherTomorrow: "forecast",
+	actionExchange:        "exchange",
+	actionNewsPage:        "news",
+	actionWeatherAt:       "weather",
+	actionChoosePlace:     "weather",
+}
+
+func callbackCommand(action string) string {
+	if command, ok := callbackCommands[action]; ok {
+		return command
+	}
+	return ratelimit.DefaultCommand
+}
+
+// loadRateLimits применяет блокировки и лимиты, сохраненные из админки
+func (b *Bot) loadRateLimits() {
+	if b.store == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	bans, err := b.store.ListBans(ctx)
+	if err != nil {
+		log.Printf("Failed to load bans: %v", err)
+		return
+	}
+	for _, ban := range bans {
+		b.limiter.Restore(ratelimit.Ban{ID: ban.ChatID, Reason: ban.Reason, Since: ban.CreatedAt})
+	}
+
+	limits, err := b.store.ListRateLimits(ctx)
+	if err != nil {
+		log.Printf("Failed to load rate limits: %v", err)
+		return
+	}
+	for _, l := range limits {
+		limit := ratelimit.Limit{Requests: l.Requests, Window: l.Window}
+		if l.Kind == storage.LimitProvider {
+			err = b.limiter.SetProviderLimit(l.Name, limit)
+		} else {
+			err = b.limiter.SetCommandLimit(l.Name, limit)
+		}
+		if err != nil {
+			log.Printf("Skipping rate limit %s %s: %v", l.Kind, l.Name, err)
+		}
+	}
+
+	log.Printf("Loaded %d bans and %d rate limits", len(bans), len(limits))
+}
+
+// banned - обновление от заблокированного чата или пользователя
+func (b *Bot) banned(update tgbotapi.Update) bool {
+	if chat := update.FromChat(); chat != nil && b.limiter.IsBanned(chat.ID) {
+		return true
+	}
+	if user := update.SentFrom(); user != nil && b.limiter.IsBanned(user.ID) {
+		return true
+	}
+	return false
+}
+
+// allowCommand проверяет лимит пользователя. При первом отказе подряд
+// отвечает, когда можно повторить, дальше молчит, чтобы не отвечать на спам спамом
+func (b *Bot) allowCommand(chatID int64, from *tgbotapi.User, command string) bool {
+	userID := chatID
+	if from != nil {
+		userID = from.ID
+	}
+
+	decision := b.limiter.Allow(userID, command)
+	if decision.Allowed {
+		return true
+	}
+
+	if decision.Notify {
+		lang := b.lang(chatID)
+		b.sendMessage(chatID, i18n.T(lang, "ratelimit.user", i18n.Duration(decision.RetryAfter)))
+	}
+	log.Printf("Rate limited user %d on %s, retry in %s", userID, command, decision.RetryAfter)
+	return false
+}
@@ -0,0 +1,150 @@
+package bot
+
+import (
+	"dailybot/internal/api"
+	"dailybot/internal/i18n"
+	"dailybot/internal/storage"
+	"log"
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// handleLocation показывает погоду по присланной геопозиции
+func (b *Bot) handleLocation(chatID int64, location *tgbotapi.Location) {
+	lang := b.lang(chatID)
+	lat, lon := location.Latitude, location.Longitude
+	b.admin.LogCommand(chatID, "location", api.FormatCoordinates(lat, lon))
+
+	b.respondWithWeatherAt(chatID, storage.Location{Lat: lat, Lon: lon}, lang)
+}
+
+func (b *Bot) respondWithWeatherAt(chatID int64, location storage.Location, lang i18n.Lang) {
+	units := b.units(chatID)
+	b.respondWithProgress(chatID, i18n.T(lang, "weather.loading"), func() (reply, error) {
+		weatherInfo, err := b.currentWeather(api.Place{Lat: location.Lat, Lon: location.Lon}, lang, units)
+		return reply{text: weatherInfo, keyboard: b.locationWeatherKeyboard(chatID, location, lang)}, err
+	})
+}
+
+// locationWeatherKeyboard - кнопки под погодой по координатам. Кнопка сохранения
+// не нужна, если это место уже выбрано по умолчанию
+func (b *Bot) locationWeatherKeyboard(chatID int64, location storage.Location, lang i18n.Lang) *tgbotapi.InlineKeyboardMarkup {
+	lat, lon := coordinateArg(location.Lat), coordinateArg(location.Lon)
+
+	var save *tgbotapi.InlineKeyboardButton
+	if saved := b.profile(chatID).Location; saved == nil || coordinateArg(saved.Lat) != lat || coordinateArg(saved.Lon) != lon {
+		save = inlineButton(i18n.T(lang, "button.save_location"), actionSaveLocation, lat, lon)
+	}
+
+	return inlineRows(
+		[]*tgbotapi.InlineKeyboardButton{inlineButton(i18n.T(lang, "button.refresh"), actionWeatherAt, lat, lon)},
+		[]*tgbotapi.InlineKeyboardButton{save},
+	)
+}
+
+func (b *Bot) callbackWeatherAt(query *tgbotapi.CallbackQuery, args []string) string {
+	chatID := query.Message.Chat.ID
+	lang := b.lang(chatID)
+	location, ok := parseLocationArgs(args)
+	if !ok {
+		return i18n.T(lang, "callback.outdated")
+	}
+	b.admin.LogCommand(chatID, "location", api.FormatCoordinates(location.Lat, location.Lon))
+
+	weatherInfo, err := b.currentWeather(api.Place{Lat: location.Lat, Lon: location.Lon}, lang, b.units(chatID))
+	if err != nil {
+		return i18n.ErrorText(lang, err)
+	}
+
+	b.editMessage(chatID, query.Message.MessageID, weatherInfo, b.locationWeatherKeyboard(chatID, location, lang))
+	return ""
+}
+
+// savedPlace - место по сохраненной геопозиции: погода ищется по координатам,
+// а название только показывается. Геокодер его часто не найдет: у Open-Meteo
+// это просто координаты
+func savedPlace(name string, location storage.Location) api.Place {
+	return api.Place{Name: name, Lat: location.Lat, Lon: location.Lon}
+}
+
+// callbackSaveLocation делает место местом по умолчанию. Погода, прогноз
+// и дайджест ищутся по координатам, а название нужно для показа
+func (b *Bot) callbackSaveLocation(query *tgbotapi.CallbackQuery, args []string) string {
+	chatID := query.Message.Chat.ID
+	lang := b.lang(chatID)
+	location, ok := parseLocationArgs(args)
+	if !ok {
+		return i18n.T(lang, "callback.outdated")
+	}
+
+	name, err := b.weather.PlaceName(location.Lat, location.Lon, lang)
+	if err != nil {
+		return i18n.ErrorText(lang, err)
+	}
+
+	_, err = b.updateProfile(chatID, func(p *storage.Profile) {
+		p.City = name
+		p.Location = &location
+	})
+	if err != nil {
+		log.Printf("Failed to save profile for chat %d: %v", chatID, err)
+		return i18n.T(lang, "settings.err.save")
+	}
+
+	// Убираем кнопку сохранения
+	if keyboard := b.locationWeatherKeyboard(chatID, location, lang); keyboard != nil {
+		edit := tgbotapi.NewEditMessageReplyMarkup(chatID, query.Message.MessageID, *keyboard)
+		if _, err := b.api.Request(edit); err != nil {
+			log.Printf("Failed to edit keyboard of message %d in chat %d: %v", query.Message.MessageID, chatID, err)
+		}
+	}
+
+	return i18n.T(lang, "location.saved", name)
+}
+
+// coordinateArg - координата для данных кнопки: четырех знаков хватает
+// с точностью около 10 метров и укладывается в лимит callback_data
+func coordinateArg(value float64) string {
+	return strconv.FormatFloat(value, 'f', 4, 64)
+}
+
+func parseLocationArgs(args []string) (storage.Location, bool) {
+	if len(args) != 2 {
+		return storage.Location{}, false
+	}
+
+	lat, err := strconv.ParseFloat(args[0], 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return storage.Location{}, false
+	}
+	lon, err := strconv.ParseFloat(args[1], 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return storage.Location{}, false
+	}
+
+	return storage.Location{Lat: lat, Lon: lon}, true
+}
+
+// sendLocationRequest отправляет сообщение с кнопкой "Отправить геопозицию".
+// Telegram разрешает такие кнопки только в личных чатах, а у них ID положительный
+func (b *Bot) sendLocationRequest(chatID int64, text string) {
+	if chatID < 0 {
+		b.sendMessage(chatID, text)
+		return
+	}
+
+	lang := b.lang(chatID)
+	keyboard := tgbotapi.NewReplyKeyboard(
+		tgbotapi.NewKeyboardButtonRow(tgbotapi.NewKeyboardButtonLocation(i18n.T(lang, "button.send_location"))),
+	)
+	keyboard.ResizeKeyboard = true
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = keyboard
+
+	if _, err := b.api.Send(msg); err != nil {
+		log.Printf("Failed to send message to chat %d: %v", chatID, err)
+	}
+}
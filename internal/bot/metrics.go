@@ -0,0 +1,46 @@
+package bot
+
+import (
+	"dailybot/internal/ratelimit"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Команды, которые попадают в метки метрик как есть. Остальные считаются
+// вместе, чтобы произвольный текст после "/" не плодил временные ряды
+var knownCommands = map[string]bool{
+	"start": true, "help": true, "weather": true, "forecast": true, "news": true,
+	"exchange": true, "convert": true, "alert": true, "alerts": true,
+	"subscribe": true, "unsubscribe": true, "subscription": true,
+	"lang": true, "settings": true,
+}
+
+// metricCommand - метка команды для метрик. Кнопки считаются в свою
+// команду, как и в лимитах, геопозиция - в погоду
+func metricCommand(update tgbotapi.Update) string {
+	switch {
+	case update.Message != nil:
+		message := update.Message
+		command := message.Command()
+		switch {
+		case knownCommands[command]:
+			return command
+		case message.Location != nil:
+			return "weather"
+		case message.IsCommand():
+			return "unknown"
+		}
+		return "message"
+
+	case update.CallbackQuery != nil:
+		action, _, ok := parseCallbackData(update.CallbackQuery.Data)
+		if command := callbackCommand(action); ok && command != ratelimit.DefaultCommand {
+			return command
+		}
+		return "callback"
+
+	case update.InlineQuery != nil:
+		return "inline"
+	}
+	return "other"
+}
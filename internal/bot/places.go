@@ -0,0 +1,213 @@
+package bot
+
+import (
+	"context"
+	"dailybot/internal/api"
+	"dailybot/internal/i18n"
+	"dailybot/internal/storage"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const maxCitySuggestions = 3
+
+// placeOffer - одноименные места, из которых чат выбирает нужное кнопкой
+type placeOffer struct {
+	query  string
+	places []api.Place
+}
+
+func (b *Bot) loadPlaceChoices() {
+	if b.store == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	choices, err := b.store.ListPlaceChoices(ctx)
+	if err != nil {
+		log.Printf("Failed to load place choices: %v", err)
+		return
+	}
+
+	b.placesMu.Lock()
+	for _, c := range choices {
+		b.setPlaceChoiceLocked(c.ChatID, c.Query, api.Place{
+			Name:    c.Name,
+			State:   c.State,
+			Country: c.Country,
+			Lat:     c.Lat,
+			Lon:     c.Lon,
+		})
+	}
+	b.placesMu.Unlock()
+
+	log.Printf("Loaded %d place choices", len(choices))
+}
+
+// cityWeather ищет город через геокодер. Если мест несколько - предлагает выбрать,
+// если ни одного - подсказывает похожие названия. Выбор запоминается для чата
+func (b *Bot) cityWeather(chatID int64, city string, lang i18n.Lang, units api.Units) (reply, error) {
+	if place, ok := b.rememberedPlace(chatID, city); ok {
+		weatherInfo, err := b.currentWeather(place, lang, units)
+		return reply{text: weatherInfo, keyboard: weatherKeyboard(city, lang)}, err
+	}
+
+	places, err := b.weather.Geocode(city, lang)
+	if err != nil {
+		return reply{}, err
+	}
+
+	switch len(places) {
+	case 0:
+		return b.citySuggestions(city, lang)
+	case 1:
+		weatherInfo, err := b.currentWeather(places[0], lang, units)
+		return reply{text: weatherInfo, keyboard: weatherKeyboard(city, lang)}, err
+	}
+
+	b.placesMu.Lock()
+	b.placeOffers[chatID] = placeOffer{query: city, places: places}
+	b.placesMu.Unlock()
+
+	buttons := make([][]*tgbotapi.InlineKeyboardButton, 0, len(places))
+	for i, place := range places {
+		buttons = append(buttons, []*tgbotapi.InlineKeyboardButton{
+			inlineButton(place.Label(), actionChoosePlace, strconv.Itoa(i)),
+		})
+	}
+
+	return reply{
+		text:     i18n.T(lang, "weather.choose_place", html.EscapeString(city)),
+		keyboard: inlineRows(buttons...),
+	}, nil
+}
+
+// citySuggestions - ответ, когда город не найден: похожие названия кнопками
+func (b *Bot) citySuggestions(city string, lang i18n.Lang) (reply, error) {
+	notFound := i18n.NewError("weather.err.city_not_found", html.EscapeString(city))
+
+	suggestions := api.SuggestCities(city, maxCitySuggestions)
+	if len(suggestions) == 0 {
+		return reply{}, notFound
+	}
+
+	buttons := make([]*tgbotapi.InlineKeyboardButton, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		buttons = append(buttons, inlineButton(suggestion, actionWeatherRefresh, suggestion))
+	}
+
+	text := i18n.T(lang, "error", i18n.ErrorText(lang, notFound)) + i18n.T(lang, "weather.did_you_mean")
+	return reply{text: text, keyboard: inlineKeyboard(buttons...)}, nil
+}
+
+// placeWeather - погода без вопросов пользователю (inline-режим, дайджест)
+func (b *Bot) placeWeather(chatID int64, city string, lang i18n.Lang, units api.Units) (string, error) {
+	place, err := b.resolvePlace(chatID, city, lang)
+	if err != nil {
+		return "", err
+	}
+	return b.currentWeather(place, lang, units)
+}
+
+// currentWeather - текущая погода в месте, готовая к отправке
+func (b *Bot) currentWeather(place api.Place, lang i18n.Lang, units api.Units) (string, error) {
+	weather, err := b.weather.Current(place, lang, units)
+	if err != nil {
+		return "", err
+	}
+	return renderer(lang).Weather(weather), nil
+}
+
+// resolvePlace - запомненное для чата место, а если его нет - первое
+// найденное геокодером
+func (b *Bot) resolvePlace(chatID int64, city string, lang i18n.Lang) (api.Place, error) {
+	if place, ok := b.rememberedPlace(chatID, city); ok {
+		return place, nil
+	}
+	return b.weather.Locate(city, lang)
+}
+
+func (b *Bot) callbackChoosePlace(query *tgbotapi.CallbackQuery, args []string) string {
+	chatID := query.Message.Chat.ID
+	lang := b.lang(chatID)
+	if len(args) != 1 {
+		return i18n.T(lang, "callback.outdated")
+	}
+
+	index, err := strconv.Atoi(args[0])
+	b.placesMu.Lock()
+	offer, exists := b.placeOffers[chatID]
+	b.placesMu.Unlock()
+	if err != nil || !exists || index < 0 || index >= len(offer.places) {
+		return i18n.T(lang, "callback.outdated")
+	}
+	place := offer.places[index]
+	b.admin.LogCommand(chatID, "weather", place.Label())
+
+	weatherInfo, err := b.currentWeather(place, lang, b.units(chatID))
+	if err != nil {
+		return i18n.ErrorText(lang, err)
+	}
+
+	b.rememberPlace(chatID, offer.query, place)
+	b.editMessage(chatID, query.Message.MessageID, weatherInfo, weatherKeyboard(offer.query, lang))
+	return ""
+}
+
+func (b *Bot) rememberedPlace(chatID int64, city string) (api.Place, bool) {
+	b.placesMu.Lock()
+	defer b.placesMu.Unlock()
+
+	place, ok := b.placeChoices[chatID][placeQuery(city)]
+	return place, ok
+}
+
+// rememberPlace запоминает выбор, чтобы больше не спрашивать про это название
+func (b *Bot) rememberPlace(chatID int64, city string, place api.Place) {
+	query := placeQuery(city)
+
+	b.placesMu.Lock()
+	delete(b.placeOffers, chatID)
+	b.setPlaceChoiceLocked(chatID, query, place)
+	b.placesMu.Unlock()
+
+	if b.store == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := b.store.SavePlaceChoice(ctx, storage.PlaceChoice{
+		ChatID:  chatID,
+		Query:   query,
+		Name:    place.Name,
+		State:   place.State,
+		Country: place.Country,
+		Lat:     place.Lat,
+		Lon:     place.Lon,
+	})
+	if err != nil {
+		log.Printf("Failed to save place choice for chat %d: %v", chatID, err)
+	}
+}
+
+func (b *Bot) setPlaceChoiceLocked(chatID int64, query string, place api.Place) {
+	if b.placeChoices[chatID] == nil {
+		b.placeChoices[chatID] = make(map[string]api.Place)
+	}
+	b.placeChoices[chatID][query] = place
+}
+
+// placeQuery приводит название к виду, в котором хранится выбор:
+// "  Нижний   Новгород " и "нижний новгород" - одно и то же
+func placeQuery(city string) string {
+	return strings.ToLower(strings.Join(strings.Fields(city), " "))
+}
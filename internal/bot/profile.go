@@ -0,0 +1,95 @@
+package bot
+
+import (
+	"context"
+	"dailybot/internal/api"
+	"dailybot/internal/storage"
+	"log"
+	"time"
+)
+
+// Профиль хранится по ID чата. В личном чате он совпадает с ID пользователя,
+// поэтому настройки действуют и для inline-запросов, и для рассылок.
+
+func (b *Bot) loadProfiles() {
+	if b.store == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	profiles, err := b.store.ListProfiles(ctx)
+	if err != nil {
+		log.Printf("Failed to load profiles: %v", err)
+		return
+	}
+
+	b.profilesMu.Lock()
+	for _, p := range profiles {
+		b.profiles[p.ChatID] = p
+	}
+	b.profilesMu.Unlock()
+
+	log.Printf("Loaded %d user profiles", len(profiles))
+}
+
+// profile возвращает копию профиля. Если профиля нет - пустой профиль со значениями по умолчанию
+func (b *Bot) profile(chatID int64) storage.Profile {
+	b.profilesMu.RLock()
+	defer b.profilesMu.RUnlock()
+
+	if p, ok := b.profiles[chatID]; ok {
+		p.Currencies = append([]string(nil), p.Currencies...)
+		if p.Location != nil {
+			location := *p.Location
+			p.Location = &location
+		}
+		return p
+	}
+	return storage.Profile{ChatID: chatID, Units: string(api.Metric)}
+}
+
+// updateProfile применяет изменение к профилю и сохраняет его. Чтение,
+// изменение и запись идут без блокировки: профиль меняют только обработчики
+// обновлений его чата, а диспетчер выполняет их строго по очереди. Вызывать
+// из фоновых задач или другого чата нельзя - изменения могут потеряться
+func (b *Bot) updateProfile(chatID int64, change func(p *storage.Profile)) (storage.Profile, error) {
+	p := b.profile(chatID)
+	change(&p)
+
+	if b.store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := b.store.SaveProfile(ctx, p); err != nil {
+			return p, err
+		}
+	}
+
+	b.profilesMu.Lock()
+	b.profiles[chatID] = p
+	b.profilesMu.Unlock()
+
+	return p, nil
+}
+
+func (b *Bot) units(chatID int64) api.Units {
+	return api.ParseUnits(b.profile(chatID).Units)
+}
+
+// timezone - часовой пояс пользователя, по умолчанию - из конфига
+func (b *Bot) timezone(chatID int64) string {
+	if tz := b.profile(chatID).Timezone; tz != "" {
+		return tz
+	}
+	return b.config.Timezone
+}
+
+// favoriteCurrencies - валюты для быстрых кнопок: избранные или популярные
+func (b *Bot) favoriteCurrencies(chatID int64) []string {
+	if favorites := b.profile(chatID).Currencies; len(favorites) > 0 {
+		return favorites
+	}
+	return quickCurrencies
+}
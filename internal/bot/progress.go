@@ -0,0 +1,58 @@
+package bot
+
+import (
+	"dailybot/internal/i18n"
+	"log"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Если ответ готов быстрее, заглушка "Получаю данные..." не нужна:
+// пользователю хватит индикатора "печатает"
+const placeholderDelay = 700 * time.Millisecond
+
+// reply - результат запроса к внешнему API для отправки пользователю
+type reply struct {
+	text     string
+	keyboard *tgbotapi.InlineKeyboardMarkup
+}
+
+// respondWithProgress показывает индикатор "печатает", пока выполняется fetch.
+// Если запрос затянулся, отправляет заглушку и потом превращает ее в результат
+// или в сообщение об ошибке. Если отредактировать не удалось, отправляет новое сообщение.
+func (b *Bot) respondWithProgress(chatID int64, placeholder string, fetch func() (reply, error)) {
+	if _, err := b.api.Request(tgbotapi.NewChatAction(chatID, tgbotapi.ChatTyping)); err != nil {
+		log.Printf("Failed to send chat action to chat %d: %v", chatID, err)
+	}
+
+	type result struct {
+		reply reply
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		r, err := fetch()
+		done <- result{r, err}
+	}()
+
+	var res result
+	placeholderID := 0
+	select {
+	case res = <-done:
+	case <-time.After(placeholderDelay):
+		placeholderID = b.sendMessage(chatID, placeholder)
+		res = <-done
+	}
+
+	out := res.reply
+	if res.err != nil {
+		lang := b.lang(chatID)
+		out = reply{text: i18n.T(lang, "error", i18n.ErrorText(lang, res.err))}
+	}
+
+	if placeholderID != 0 && b.editMessage(chatID, placeholderID, out.text, out.keyboard) == nil {
+		return
+	}
+	b.sendMessageWithKeyboard(chatID, out.text, out.keyboard)
+}
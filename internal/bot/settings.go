@@ -0,0 +1,366 @@
+package bot
+
+import (
+	"dailybot/internal/api"
+	"dailybot/internal/i18n"
+	"dailybot/internal/storage"
+	"html"
+	"log"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Поля профиля в данных кнопок и в ожидании ввода
+const (
+	fieldMain       = "main"
+	fieldCity       = "city"
+	fieldCurrencies = "cur"
+	fieldLang       = "lang"
+	fieldUnits      = "units"
+	fieldTimezone   = "tz"
+	fieldDigest     = "digest"
+
+	digestOff     = "off"
+	maxCityLength = 40
+)
+
+// Валюты, из которых выбираются избранные
+var settingsCurrencies = []string{"USD", "EUR", "CNY", "GBP", "JPY", "CHF", "TRY", "KZT", "BYN"}
+
+// Часовые пояса для быстрого выбора, остальные вводятся текстом
+var settingsTimezones = []string{"Europe/Kaliningrad", "Europe/Moscow", "Asia/Yekaterinburg", "Asia/Novosibirsk"}
+
+// Названия языков на самих этих языках - одинаковы для любого интерфейса
+var languageNames = map[i18n.Lang]string{
+	i18n.RU: "Русский",
+	i18n.EN: "English",
+}
+
+func (b *Bot) handleSettings(chatID int64) {
+	lang := b.lang(chatID)
+	b.sendMessageWithKeyboard(chatID, b.settingsText(chatID, ""), settingsKeyboard(lang))
+}
+
+// settingsText - сводка профиля, header выводится над ней (например, "Сохранено")
+func (b *Bot) settingsText(chatID int64, header string) string {
+	lang := b.lang(chatID)
+	profile := b.profile(chatID)
+
+	city := html.EscapeString(profile.City)
+	if profile.Location != nil {
+		city += " 📍 " + api.FormatCoordinates(profile.Location.Lat, profile.Location.Lon)
+	}
+	if city == "" {
+		city = i18n.T(lang, "settings.not_set")
+	}
+
+	currencies := strings.Join(profile.Currencies, ", ")
+	if currencies == "" {
+		currencies = i18n.T(lang, "settings.not_set")
+	}
+
+	digest := i18n.T(lang, "settings.digest_off")
+	if sub, exists := b.subscription(chatID); exists {
+		digest = sub.Time
+	}
+
+	return header + i18n.T(lang, "settings.summary",
+		city,
+		currencies,
+		languageNames[lang],
+		i18n.T(lang, "settings.units."+string(api.ParseUnits(profile.Units))),
+		b.timezone(chatID),
+		digest)
+}
+
+func settingsKeyboard(lang i18n.Lang) *tgbotapi.InlineKeyboardMarkup {
+	button := func(label, field string) *tgbotapi.InlineKeyboardButton {
+		return inlineButton(i18n.T(lang, label), actionSettings, field)
+	}
+
+	return inlineRows(
+		[]*tgbotapi.InlineKeyboardButton{button("button.city", fieldCity), button("button.currencies", fieldCurrencies)},
+		[]*tgbotapi.InlineKeyboardButton{button("button.language", fieldLang), button("button.units", fieldUnits)},
+		[]*tgbotapi.InlineKeyboardButton{button("button.timezone", fieldTimezone), button("button.digest", fieldDigest)},
+	)
+}
+
+func backButton(lang i18n.Lang) []*tgbotapi.InlineKeyboardButton {
+	return []*tgbotapi.InlineKeyboardButton{inlineButton(i18n.T(lang, "button.back"), actionSettings, fieldMain)}
+}
+
+// callbackSettings открывает раздел настроек. Поля со свободным вводом
+// (город, часовой пояс, время дайджеста) ждут значение следующим сообщением
+func (b *Bot) callbackSettings(query *tgbotapi.CallbackQuery, args []string) string {
+	chatID := query.Message.Chat.ID
+	lang := b.lang(chatID)
+	if len(args) != 1 {
+		return i18n.T(lang, "callback.outdated")
+	}
+
+	field := args[0]
+	b.setPendingInput(chatID, "")
+
+	var text string
+	var keyboard *tgbotapi.InlineKeyboardMarkup
+
+	switch field {
+	case fieldMain:
+		text, keyboard = b.settingsText(chatID, ""), settingsKeyboard(lang)
+
+	case fieldCity:
+		b.setPendingInput(chatID, fieldCity)
+		text, keyboard = i18n.T(lang, "settings.city_prompt"), inlineRows(backButton(lang))
+
+	case fieldCurrencies:
+		text, keyboard = i18n.T(lang, "settings.currencies_prompt"), b.currencySettingsKeyboard(chatID)
+
+	case fieldLang:
+		var buttons []*tgbotapi.InlineKeyboardButton
+		for _, option := range []i18n.Lang{i18n.RU, i18n.EN} {
+			buttons = append(buttons, inlineButton(languageNames[option], actionSettingsSet, fieldLang, string(option)))
+		}
+		text, keyboard = i18n.T(lang, "settings.lang_prompt"), inlineRows(buttons, backButton(lang))
+
+	case fieldUnits:
+		buttons := []*tgbotapi.InlineKeyboardButton{
+			inlineButton(i18n.T(lang, "settings.units."+string(api.Metric)), actionSettingsSet, fieldUnits, string(api.Metric)),
+			inlineButton(i18n.T(lang, "settings.units."+string(api.Imperial)), actionSettingsSet, fieldUnits, string(api.Imperial)),
+		}
+		text, keyboard = i18n.T(lang, "settings.units_prompt"), inlineRows(buttons[:1], buttons[1:], backButton(lang))
+
+	case fieldTimezone:
+		b.setPendingInput(chatID, fieldTimezone)
+		var rows [][]*tgbotapi.InlineKeyboardButton
+		for _, tz := range settingsTimezones {
+			rows = append(rows, []*tgbotapi.InlineKeyboardButton{inlineButton(tz, actionSettingsSet, fieldTimezone, tz)})
+		}
+		text, keyboard = i18n.T(lang, "settings.tz_prompt"), inlineRows(append(rows, backButton(lang))...)
+
+	case fieldDigest:
+		b.setPendingInput(chatID, fieldDigest)
+		off := []*tgbotapi.InlineKeyboardButton{inlineButton(i18n.T(lang, "button.digest_off"), actionSettingsSet, fieldDigest, digestOff)}
+		text, keyboard = i18n.T(lang, "settings.digest_prompt"), inlineRows(off, backButton(lang))
+
+	default:
+		return i18n.T(lang, "callback.outdated")
+	}
+
+	b.editMessage(chatID, query.Message.MessageID, text, keyboard)
+	return ""
+}
+
+// callbackSettingsSet сохраняет значение, выбранное кнопкой
+func (b *Bot) callbackSettingsSet(query *tgbotapi.CallbackQuery, args []string) string {
+	chatID := query.Message.Chat.ID
+	lang := b.lang(chatID)
+	if len(args) != 2 {
+		return i18n.T(lang, "callback.outdated")
+	}
+
+	field, value := args[0], args[1]
+	b.setPendingInput(chatID, "")
+
+	if field == fieldCurrencies {
+		if err := b.toggleFavorite(chatID, value); err != nil {
+			return i18n.ErrorText(lang, err)
+		}
+		b.editMessage(chatID, query.Message.MessageID, i18n.T(lang, "settings.currencies_prompt"), b.currencySettingsKeyboard(chatID))
+		return ""
+	}
+
+	if err := b.applySetting(chatID, field, value); err != nil {
+		return i18n.ErrorText(lang, err)
+	}
+
+	// Язык мог поменяться - перерисовываем на новом
+	lang = b.lang(chatID)
+	b.editMessage(chatID, query.Message.MessageID, b.settingsText(chatID, i18n.T(lang, "settings.saved")), settingsKeyboard(lang))
+	return ""
+}
+
+// handleSettingsInput принимает текстовое значение поля, которое ждет бот
+func (b *Bot) handleSettingsInput(chatID int64, field, value string) {
+	lang := b.lang(chatID)
+
+	if err := b.applySetting(chatID, field, strings.TrimSpace(value)); err != nil {
+		// Ждем исправленное значение
+		b.setPendingInput(chatID, field)
+		b.sendMessage(chatID, i18n.T(lang, "error", i18n.ErrorText(lang, err)))
+		return
+	}
+
+	lang = b.lang(chatID)
+	b.sendMessageWithKeyboard(chatID, b.settingsText(chatID, i18n.T(lang, "settings.saved")), settingsKeyboard(lang))
+}
+
+// applySetting проверяет и сохраняет значение поля профиля
+func (b *Bot) applySetting(chatID int64, field, value string) error {
+	var change func(p *storage.Profile)
+
+	switch field {
+	case fieldCity:
+		if value == "" || utf8.RuneCountInString(value) > maxCityLength {
+			return i18n.NewError("settings.err.city", maxCityLength)
+		}
+		// Город, введенный текстом, заменяет сохраненную геопозицию
+		change = func(p *storage.Profile) {
+			p.City = value
+			p.Location = nil
+		}
+
+	case fieldLang:
+		lang, ok := i18n.Parse(value)
+		if !ok {
+			return i18n.NewError("callback.outdated")
+		}
+		change = func(p *storage.Profile) { p.Lang = string(lang) }
+
+	case fieldUnits:
+		change = func(p *storage.Profile) { p.Units = string(api.ParseUnits(value)) }
+
+	case fieldTimezone:
+		if _, err := time.LoadLocation(value); err != nil || value == "" || value == "Local" {
+			return i18n.NewError("settings.err.timezone", html.EscapeString(value))
+		}
+		change = func(p *storage.Profile) { p.Timezone = value }
+
+	case fieldDigest:
+		return b.setDigestTime(chatID, value)
+
+	default:
+		return i18n.NewError("callback.outdated")
+	}
+
+	if _, err := b.updateProfile(chatID, change); err != nil {
+		log.Printf("Failed to save profile for chat %d: %v", chatID, err)
+		return i18n.NewError("settings.err.save")
+	}
+
+	// Дайджест отправляется по часовому поясу подписки
+	if field == fieldTimezone {
+		if sub, exists := b.subscription(chatID); exists {
+			sub.Timezone = value
+			b.skipPassedDigest(&sub)
+			if err := b.saveSubscription(sub); err != nil {
+				log.Printf("Failed to update subscription timezone for chat %d: %v", chatID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// setDigestTime включает дайджест на время ЧЧ:ММ или выключает его ("off")
+func (b *Bot) setDigestTime(chatID int64, value string) error {
+	if value == digestOff {
+		b.deleteSubscription(chatID)
+		return nil
+	}
+
+	sendAt, err := time.Parse("15:04", value)
+	if err != nil {
+		return i18n.NewError("subscribe.err.time")
+	}
+
+	sub, exists := b.subscription(chatID)
+	if exists {
+		sub.Time = sendAt.Format("15:04")
+		b.skipPassedDigest(&sub)
+	} else {
+		sub = b.newSubscription(chatID, sendAt.Format("15:04"))
+	}
+
+	if err := b.saveSubscription(sub); err != nil {
+		log.Printf("Failed to save subscription for chat %d: %v", chatID, err)
+		return i18n.NewError("subscribe.err.save")
+	}
+	return nil
+}
+
+func (b *Bot) toggleFavorite(chatID int64, code string) error {
+	if !slices.Contains(settingsCurrencies, code) {
+		return i18n.NewError("callback.outdated")
+	}
+
+	_, err := b.updateProfile(chatID, func(p *storage.Profile) {
+		if i := slices.Index(p.Currencies, code); i >= 0 {
+			p.Currencies = slices.Delete(p.Currencies, i, i+1)
+		} else {
+			p.Currencies = append(p.Currencies, code)
+		}
+	})
+	if err != nil {
+		log.Printf("Failed to save profile for chat %d: %v", chatID, err)
+		return i18n.NewError("settings.err.save")
+	}
+	return nil
+}
+
+// currencySettingsKeyboard - валюты по три в ряд, избранные отмечены галочкой
+func (b *Bot) currencySettingsKeyboard(chatID int64) *tgbotapi.InlineKeyboardMarkup {
+	favorites := b.profile(chatID).Currencies
+
+	var rows [][]*tgbotapi.InlineKeyboardButton
+	for i, code := range settingsCurrencies {
+		if i%3 == 0 {
+			rows = append(rows, nil)
+		}
+		label := code
+		if slices.Contains(favorites, code) {
+			label = "✅ " + code
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], inlineButton(label, actionSettingsSet, fieldCurrencies, code))
+	}
+
+	return inlineRows(append(rows, backButton(b.lang(chatID)))...)
+}
+
+// Сколько ждем от чата значение поля профиля. Потом следующее сообщение
+// снова обрабатывается как обычное
+const pendingInputTTL = 10 * time.Minute
+
+// pendingInput - поле профиля, значение которого ждем, и до какого времени
+type pendingInput struct {
+	field   string
+	expires time.Time
+}
+
+// setPendingInput запоминает, значение какого поля ждем от чата. Пустое поле - ничего не ждем
+func (b *Bot) setPendingInput(chatID int64, field string) {
+	now := time.Now()
+
+	b.profilesMu.Lock()
+	defer b.profilesMu.Unlock()
+
+	// Просроченные ожидания чистим по ходу: чаты, которые так и не ответили,
+	// иначе остались бы в памяти навсегда
+	for id, pending := range b.pendingInput {
+		if now.After(pending.expires) {
+			delete(b.pendingInput, id)
+		}
+	}
+
+	if field == "" {
+		delete(b.pendingInput, chatID)
+		return
+	}
+	b.pendingInput[chatID] = pendingInput{field: field, expires: now.Add(pendingInputTTL)}
+}
+
+// takePendingInput возвращает ожидаемое поле и сбрасывает ожидание
+func (b *Bot) takePendingInput(chatID int64) (string, bool) {
+	b.profilesMu.Lock()
+	defer b.profilesMu.Unlock()
+
+	pending, ok := b.pendingInput[chatID]
+	delete(b.pendingInput, chatID)
+	if !ok || time.Now().After(pending.expires) {
+		return "", false
+	}
+	return pending.field, true
+}
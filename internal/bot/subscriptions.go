@@ -0,0 +1,323 @@
+package bot
+
+import (
+	"context"
+	"dailybot/internal/api"
+	"dailybot/internal/i18n"
+	"dailybot/internal/storage"
+	"html"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var currencyCodeRe = regexp.MustCompile(`^[A-Za-z]{3}$`)
+
+// В /subscribe валюты пишутся заглавными: иначе город из трех букв
+// ("Ufa", "Rio") принимался бы за код валюты
+var digestCurrencyRe = regexp.MustCompile(`^[A-Z]{3}$`)
+
+const (
+	defaultDigestCity = "Москва"
+	schedulerInterval = 30 * time.Second
+	// Неотправленный дайджест повторяем не на каждом тике: если бот
+	// заблокирован в чате, Telegram будет отказывать до конца дня
+	digestRetryDelay = 5 * time.Minute
+)
+
+var defaultDigestCurrencies = []string{"USD", "EUR"}
+
+func (b *Bot) loadSubscriptions() {
+	if b.store == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	subs, err := b.store.ListSubscriptions(ctx)
+	if err != nil {
+		log.Printf("Failed to load subscriptions: %v", err)
+		return
+	}
+
+	b.subsMu.Lock()
+	for _, sub := range subs {
+		b.subs[sub.ChatID] = sub
+	}
+	b.subsMu.Unlock()
+
+	log.Printf("Loaded %d digest subscriptions", len(subs))
+}
+
+func (b *Bot) handleSubscribe(chatID int64, args string) {
+	lang := b.lang(chatID)
+
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		b.sendMessage(chatID, i18n.T(lang, "subscribe.usage"))
+		return
+	}
+
+	sendAt, err := time.Parse("15:04", fields[0])
+	if err != nil {
+		b.sendMessage(chatID, i18n.T(lang, "error", i18n.T(lang, "subscribe.err.time")))
+		return
+	}
+
+	// Валютные коды идут в конце: все, что до них, - название города
+	rest := fields[1:]
+	var currencies []string
+	for len(rest) > 0 && digestCurrencyRe.MatchString(rest[len(rest)-1]) {
+		currencies = append([]string{rest[len(rest)-1]}, currencies...)
+		rest = rest[:len(rest)-1]
+	}
+
+	sub := b.newSubscription(chatID, sendAt.Format("15:04"))
+	if city := strings.Join(rest, " "); city != "" {
+		sub.City = city
+		sub.Location = nil
+	}
+	if len(currencies) > 0 {
+		sub.Currencies = currencies
+	}
+
+	if err := b.saveSubscription(sub); err != nil {
+		log.Printf("Failed to save subscription for chat %d: %v", chatID, err)
+		b.sendMessage(chatID, i18n.T(lang, "error", i18n.T(lang, "subscribe.err.save")))
+		return
+	}
+
+	b.sendMessage(chatID, i18n.T(lang, "subscribe.done")+formatSubscription(sub, lang))
+}
+
+func (b *Bot) handleUnsubscribe(chatID int64) {
+	lang := b.lang(chatID)
+
+	if !b.deleteSubscription(chatID) {
+		b.sendMessage(chatID, i18n.T(lang, "subscribe.none"))
+		return
+	}
+
+	b.sendMessage(chatID, i18n.T(lang, "subscribe.cancelled"))
+}
+
+func (b *Bot) handleSubscription(chatID int64) {
+	lang := b.lang(chatID)
+
+	sub, exists := b.subscription(chatID)
+	if !exists {
+		b.sendMessage(chatID, i18n.T(lang, "subscribe.none")+i18n.T(lang, "subscribe.hint"))
+		return
+	}
+
+	b.sendMessage(chatID, i18n.T(lang, "subscribe.current")+formatSubscription(sub, lang))
+}
+
+// newSubscription собирает подписку на время sendAt. Город, валюты
+// и часовой пояс берутся из профиля, а если там пусто - по умолчанию
+func (b *Bot) newSubscription(chatID int64, sendAt string) storage.Subscription {
+	profile := b.profile(chatID)
+
+	sub := storage.Subscription{
+		ChatID:     chatID,
+		Time:       sendAt,
+		City:       profile.City,
+		Currencies: profile.Currencies,
+		Timezone:   b.timezone(chatID),
+	}
+	if profile.Location != nil {
+		location := *profile.Location
+		sub.Location = &location
+	}
+	if sub.City == "" {
+		sub.City = defaultDigestCity
+	}
+	if len(sub.Currencies) == 0 {
+		sub.Currencies = defaultDigestCurrencies
+	}
+
+	b.skipPassedDigest(&sub)
+	return sub
+}
+
+// skipPassedDigest: если время сегодня уже прошло, первый дайджест придет завтра
+func (b *Bot) skipPassedDigest(sub *storage.Subscription) {
+	sub.LastSent = ""
+	if now := b.localNow(*sub); now.Format("15:04") >= sub.Time {
+		sub.LastSent = now.Format("2006-01-02")
+	}
+}
+
+// subscription возвращает подписку чата, если она есть
+func (b *Bot) subscription(chatID int64) (storage.Subscription, bool) {
+	b.subsMu.RLock()
+	defer b.subsMu.RUnlock()
+
+	sub, exists := b.subs[chatID]
+	return sub, exists
+}
+
+// deleteSubscription удаляет подписку. false - подписки не было
+func (b *Bot) deleteSubscription(chatID int64) bool {
+	b.subsMu.Lock()
+	_, exists := b.subs[chatID]
+	delete(b.subs, chatID)
+	b.subsMu.Unlock()
+
+	if !exists {
+		return false
+	}
+
+	if b.store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := b.store.DeleteSubscription(ctx, chatID); err != nil {
+			log.Printf("Failed to delete subscription for chat %d: %v", chatID, err)
+		}
+	}
+
+	return true
+}
+
+func (b *Bot) saveSubscription(sub storage.Subscription) error {
+	if b.store != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if err := b.store.SaveSubscription(ctx, sub); err != nil {
+			return err
+		}
+	}
+
+	b.subsMu.Lock()
+	b.subs[sub.ChatID] = sub
+	b.subsMu.Unlock()
+
+	return nil
+}
+
+func formatSubscription(sub storage.Subscription, lang i18n.Lang) string {
+	return i18n.T(lang, "subscribe.details",
+		sub.Time,
+		sub.Timezone,
+		html.EscapeString(sub.City),
+		strings.Join(sub.Currencies, ", "))
+}
+
+func (b *Bot) localNow(sub storage.Subscription) time.Time {
+	loc, err := time.LoadLocation(sub.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	return time.Now().In(loc)
+}
+
+// runScheduler раз в schedulerInterval проверяет, кому пора отправить дайджест.
+// Дата последней отправки хранится в подписке, поэтому после рестарта
+// дайджест не дублируется, а пропущенный сегодня - досылается.
+func (b *Bot) runScheduler() {
+	defer b.background.Done()
+
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.sendDueDigests()
+		case <-b.stopping:
+			return
+		}
+	}
+}
+
+func (b *Bot) sendDueDigests() {
+	b.subsMu.RLock()
+	var due []storage.Subscription
+	for _, sub := range b.subs {
+		now := b.localNow(sub)
+		if now.Format("15:04") >= sub.Time && sub.LastSent != now.Format("2006-01-02") &&
+			now.After(b.digestRetry[sub.ChatID]) {
+			due = append(due, sub)
+		}
+	}
+	b.subsMu.RUnlock()
+
+	for _, sub := range due {
+		select {
+		case <-b.stopping:
+			return
+		default:
+		}
+
+		// Отправленным считаем только доставленный дайджест
+		if b.sendMessage(sub.ChatID, b.buildDigest(sub)) == 0 {
+			b.subsMu.Lock()
+			b.digestRetry[sub.ChatID] = time.Now().Add(digestRetryDelay)
+			b.subsMu.Unlock()
+			continue
+		}
+
+		sub.LastSent = b.localNow(sub).Format("2006-01-02")
+		b.markDigestSent(sub)
+	}
+}
+
+func (b *Bot) markDigestSent(sub storage.Subscription) {
+	b.subsMu.Lock()
+	delete(b.digestRetry, sub.ChatID)
+	// Пользователь мог отписаться или изменить подписку, пока собирался дайджест
+	if current, exists := b.subs[sub.ChatID]; exists {
+		current.LastSent = sub.LastSent
+		b.subs[sub.ChatID] = current
+	}
+	b.subsMu.Unlock()
+
+	if b.store == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := b.store.MarkDigestSent(ctx, sub.ChatID, sub.LastSent); err != nil {
+		log.Printf("Failed to mark digest sent for chat %d: %v", sub.ChatID, err)
+	}
+}
+
+func (b *Bot) buildDigest(sub storage.Subscription) string {
+	lang := b.lang(sub.ChatID)
+	parts := []string{i18n.T(lang, "digest.title")}
+
+	var weather string
+	var err error
+	if sub.Location != nil {
+		weather, err = b.currentWeather(savedPlace(sub.City, *sub.Location), lang, b.units(sub.ChatID))
+	} else {
+		weather, err = b.placeWeather(sub.ChatID, sub.City, lang, b.units(sub.ChatID))
+	}
+	if err != nil {
+		weather = i18n.T(lang, "digest.weather_error", i18n.ErrorText(lang, err))
+	}
+	parts = append(parts, weather)
+
+	for _, code := range sub.Currencies {
+		rate, err := exchangeRate(code, lang)
+		if err != nil {
+			rate = i18n.T(lang, "digest.rate_error", code, i18n.ErrorText(lang, err))
+		}
+		parts = append(parts, rate)
+	}
+
+	news, err := api.GetNews(b.config.NewsAPIKey, lang)
+	if err != nil {
+		parts = append(parts, i18n.T(lang, "digest.news_error", i18n.ErrorText(lang, err)))
+	} else {
+		parts = append(parts, renderer(lang).News(news))
+	}
+
+	return strings.Join(parts, "\n\n")
+}
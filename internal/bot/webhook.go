@@ -0,0 +1,139 @@
+package bot
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const webhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// startWebhook поднимает HTTP-сервер для приема обновлений и регистрирует
+// вебхук в Telegram. Без WEBHOOK_URL вебхук не регистрируется - так удобно
+// проверять бота локально, отправляя JSON обновлений прямо на эндпоинт.
+//
+// Порт занимается до регистрации, чтобы не отдавать Telegram адрес,
+// который никто не слушает. Stop, пришедший раньше запуска, оставляет
+// вебхук незапущенным
+func (b *Bot) startWebhook() (<-chan tgbotapi.Update, error) {
+	b.webhookMu.Lock()
+	defer b.webhookMu.Unlock()
+
+	updates := make(chan tgbotapi.Update, 100)
+
+	select {
+	case <-b.stopping:
+		close(updates)
+		return updates, nil
+	default:
+	}
+
+	listener, err := net.Listen("tcp", b.config.WebhookListen)
+	if err != nil {
+		return nil, fmt.Errorf("listen webhook: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(b.config.WebhookPath, b.webhookHandler(updates))
+
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		log.Printf("Webhook server listening on %s%s", listener.Addr(), b.config.WebhookPath)
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Webhook server failed: %v", err)
+		}
+	}()
+
+	if b.config.WebhookURL == "" {
+		log.Println("WEBHOOK_URL is empty, webhook is not registered in Telegram (local mode)")
+	} else {
+		// setWebhook в библиотеке не поддерживает secret_token, поэтому вызываем метод напрямую
+		params := tgbotapi.Params{}
+		params["url"] = b.config.WebhookURL
+		params["secret_token"] = b.config.WebhookSecret
+		if _, err := b.api.MakeRequest("setWebhook", params); err != nil {
+			server.Close()
+			return nil, fmt.Errorf("register webhook: %w", err)
+		}
+		log.Printf("Webhook registered: %s", b.config.WebhookURL)
+	}
+
+	b.webhookServer = server
+	b.webhookUpdates = updates
+	return updates, nil
+}
+
+// stopWebhook снимает вебхук в Telegram и останавливает HTTP-сервер
+func (b *Bot) stopWebhook(ctx context.Context) {
+	b.webhookMu.Lock()
+	defer b.webhookMu.Unlock()
+
+	// Вебхук не запускался или запуск не удался
+	if b.webhookServer == nil {
+		return
+	}
+
+	if b.config.WebhookURL != "" {
+		if _, err := b.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+			log.Printf("Failed to delete webhook: %v", err)
+		} else {
+			log.Println("Webhook deleted")
+		}
+	}
+
+	if err := b.webhookServer.Shutdown(ctx); err != nil {
+		// Обработчики еще могут писать в канал, закрывать его нельзя
+		log.Printf("Webhook server shutdown: %v", err)
+		return
+	}
+
+	// После Shutdown обработчики завершены и больше не пишут в канал,
+	// Start дочитывает из него принятые обновления
+	close(b.webhookUpdates)
+}
+
+func (b *Bot) webhookHandler(updates chan<- tgbotapi.Update) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		secret := r.Header.Get(webhookSecretHeader)
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(b.config.WebhookSecret)) != 1 {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		var update tgbotapi.Update
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&update); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		b.markPoll()
+
+		select {
+		case updates <- update:
+			w.WriteHeader(http.StatusOK)
+		case <-r.Context().Done():
+			// Telegram повторит доставку, если не получит 200
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+		case <-b.stopping:
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		}
+	}
+}
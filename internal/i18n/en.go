@@ -0,0 +1,365 @@
+package i18n
+
+var en = map[string]string{
+	// Общее
+	"error":             "<b>Error:</b> %s",
+	"unknown_command":   "Unknown command. Please use /help",
+	"stale_note":        "\n\n<i>The service is temporarily unavailable, showing data from %s</i>",
+	"callback.outdated": "This button is outdated, please repeat the command",
+	"weekdays":          "Sun|Mon|Tue|Wed|Thu|Fri|Sat",
+
+	// Ограничение частоты запросов
+	"ratelimit.user":     "⏳ Too many requests. Please try again in %s",
+	"ratelimit.provider": "the %s request limit is exhausted, please try again in %s",
+	"duration.seconds":   "%d s",
+	"duration.minutes":   "%d min",
+	"duration.hours":     "%d h",
+
+	// Формы: одна|много
+	"plural.day":   "day|days",
+	"plural.alert": "alert|alerts",
+
+	"start": `<b>Hi! I'm DailyBot - your everyday assistant!</b>
+
+<b>My commands:</b>
+/weather [city] - current weather
+/forecast [city] [days] - forecast for several days
+/exchange [currency] [date|7d] - Bank of Russia exchange rates
+/convert amount from to - currency conversion
+/alert USD &gt; 95 - exchange rate alert
+/news - top news of the day
+/subscribe HH:MM [city] [currencies] - daily digest
+/lang ru|en - bot language
+/settings - city, currencies and other settings
+/help - detailed help`,
+
+	"help": `<b>Command reference:</b>
+
+<b>My commands:</b>
+<b>/weather [city]</b> - get the current weather
+Example: <code>/weather London</code>
+
+<b>/forecast [city] [days]</b> - forecast for 1-5 days
+Example: <code>/forecast Berlin 3</code>
+
+<b>/news</b> - top 5 news of the day
+
+<b>/exchange [currency]</b> - Bank of Russia exchange rates
+Example: <code>/exchange USD</code> or <code>/exchange EUR</code>
+Rate on a date: <code>/exchange USD 2026-03-01</code>
+Trend over a period: <code>/exchange USD 7d</code>
+
+<b>/convert amount from to</b> - conversion at the Bank of Russia rate
+Example: <code>/convert 150 USD EUR</code> or <code>/convert 1000 RUB CNY</code>
+
+<b>/alert currency condition</b> - exchange rate alert
+Example: <code>/alert USD &gt; 95</code>, <code>/alert EUR &lt; 90</code> or <code>/alert CNY change 1%</code>
+/alerts - list alerts, <code>/alert delete ID</code> - delete
+
+<b>/subscribe HH:MM [city] [currencies]</b> - daily digest
+Weather, rates and news in one message at the chosen time
+Example: <code>/subscribe 08:00 London USD EUR</code>
+/subscription - current subscription, /unsubscribe - cancel
+
+<b>/lang ru|en</b> - bot language
+Defaults to your Telegram language
+
+<b>/settings</b> - profile settings
+Default city, favorite currencies, language, units, time zone and digest time
+
+<i>The bot is written in Go and uses official APIs</i>`,
+
+	// Язык
+	"lang.name":     "English",
+	"lang.current":  "<b>Bot language:</b> %s\n\nChange: <code>/lang ru</code> or <code>/lang en</code>",
+	"lang.unknown":  "Available languages: <code>ru</code>, <code>en</code>\n\nExample: <code>/lang ru</code>",
+	"lang.changed":  "Done, I will speak English now",
+	"lang.err.save": "failed to save the language, please try again later",
+
+	// Настройки
+	"settings.summary": `<b>Settings</b>
+
+<b>City:</b> %s
+<b>Favorite currencies:</b> %s
+<b>Language:</b> %s
+<b>Units:</b> %s
+<b>Time zone:</b> %s
+<b>Digest:</b> %s
+
+The city and currencies are used when /weather, /forecast and /exchange are called without arguments`,
+	"settings.saved":             "✅ Saved\n\n",
+	"settings.not_set":           "not set",
+	"settings.digest_off":        "off",
+	"settings.units.metric":      "metric (°C, m/s)",
+	"settings.units.imperial":    "imperial (°F, mph)",
+	"settings.city_prompt":       "Send the city name in the next message. To look up the weather by coordinates, share your location and tap \"Make it my default place\"",
+	"settings.currencies_prompt": "Select your favorite currencies",
+	"settings.lang_prompt":       "Choose a language",
+	"settings.units_prompt":      "Choose units",
+	"settings.tz_prompt":         "Choose a time zone or send its name, for example <code>Europe/London</code>",
+	"settings.digest_prompt":     "Send the daily digest time as HH:MM, for example <code>08:00</code>",
+	"settings.err.city":          "the city name must be at most %d characters long",
+	"settings.err.timezone":      "unknown time zone %s",
+	"location.saved":             "Default place: %s",
+	"settings.err.save":          "failed to save the settings, please try again later",
+
+	// Кнопки
+	"button.refresh":         "Refresh",
+	"button.tomorrow":        "Tomorrow's forecast",
+	"button.current_weather": "Current weather",
+	"button.back":            "Back",
+	"button.more_news":       "More news",
+	"button.city":            "City",
+	"button.currencies":      "Currencies",
+	"button.language":        "Language",
+	"button.units":           "Units",
+	"button.timezone":        "Time zone",
+	"button.digest":          "Digest",
+	"button.digest_off":      "Turn off digest",
+	"button.send_location":   "📍 Weather here",
+	"button.save_location":   "Make it my default place",
+
+	// Погода
+	"weather.usage":   "Specify a city to get the weather\n\nExample: <code>/weather London</code>\nOr share your location. You can set a default city in /settings",
+	"weather.loading": "Fetching the weather...",
+	"weather.clear":   "clear sky",
+	"weather.current": `<b>Weather in %s</b>
+
+<b>Temperature:</b> %d%s (feels like %d%s)
+<b>Conditions:</b> %s
+<b>Humidity:</b> %d%%`,
+	"weather.wind":        "\n<b>Wind:</b> %d %s",
+	"units.wind.metric":   "m/s",
+	"units.wind.imperial": "mph",
+	"weather.pressure":    "\n<b>Pressure:</b> %d mmHg",
+	"weather.stub": `<b>Weather in %s (demo mode)</b>
+
+<b>Temperature:</b> 22°C (feels like 24°C)
+<b>Conditions:</b> partly cloudy
+<b>Humidity:</b> 65%%
+<b>Wind:</b> 3 m/s
+<b>Pressure:</b> 760 mmHg
+
+<i>Demo mode: this data is not real. Turn off DEMO_MODE to get the actual weather</i>`,
+	"weather.stub_description": "partly cloudy",
+	"weather.demo_hint":        "\n\n<i>Demo mode: this data is not real. Turn off DEMO_MODE to get the actual weather</i>",
+	"weather.source":           "\n\n<i>Source: %s</i>",
+
+	"weather.err.connection":     "failed to connect to the weather service",
+	"weather.err.decode":         "failed to process weather data",
+	"weather.err.response":       "failed to get weather data",
+	"weather.err.city_not_found": "city '%s' not found",
+	"weather.choose_place":       "Found several places named <b>%s</b>. Choose the one you need - I will remember it",
+	"weather.did_you_mean":       "\n\nDid you mean:",
+	"weather.err.bad_key":        "invalid OpenWeather API key",
+	"weather.err.api":            "API error: %s",
+	"weather.err.status":         "weather service error (code %d)",
+
+	// Прогноз
+	"forecast.usage":               "Specify a city to get the forecast\n\nExample: <code>/forecast London 3</code>\nYou can set a default city in /settings",
+	"forecast.loading":             "Fetching the forecast...",
+	"forecast.title":               "Weather forecast for %s",
+	"forecast.tomorrow_title":      "Tomorrow's forecast for %s",
+	"forecast.stub_title":          "Weather forecast for %s (demo mode)",
+	"forecast.tomorrow_stub_title": "Tomorrow's forecast for %s (demo mode)",
+	"forecast.day": `
+
+<b>%s, %s</b>
+<b>Temperature:</b> from %d%s to %d%s
+<b>Conditions:</b> %s
+<b>Chance of precipitation:</b> %d%%`,
+
+	"forecast.err.days":     "the forecast is available for 1-%d days",
+	"forecast.err.tomorrow": "tomorrow's forecast is unavailable",
+	"forecast.err.response": "failed to get the weather forecast",
+
+	// Описания погоды по кодам WMO (Open-Meteo)
+	"wmo.0":  "clear sky",
+	"wmo.1":  "mainly clear",
+	"wmo.2":  "partly cloudy",
+	"wmo.3":  "overcast",
+	"wmo.45": "fog",
+	"wmo.48": "depositing rime fog",
+	"wmo.51": "light drizzle",
+	"wmo.53": "moderate drizzle",
+	"wmo.55": "dense drizzle",
+	"wmo.56": "light freezing drizzle",
+	"wmo.57": "dense freezing drizzle",
+	"wmo.61": "slight rain",
+	"wmo.63": "moderate rain",
+	"wmo.65": "heavy rain",
+	"wmo.66": "light freezing rain",
+	"wmo.67": "heavy freezing rain",
+	"wmo.71": "slight snow",
+	"wmo.73": "moderate snow",
+	"wmo.75": "heavy snow",
+	"wmo.77": "snow grains",
+	"wmo.80": "slight rain showers",
+	"wmo.81": "moderate rain showers",
+	"wmo.82": "violent rain showers",
+	"wmo.85": "slight snow showers",
+	"wmo.86": "heavy snow showers",
+	"wmo.95": "thunderstorm",
+	"wmo.96": "thunderstorm with slight hail",
+	"wmo.99": "thunderstorm with heavy hail",
+
+	// Курсы валют
+	"exchange.prompt":    "Choose a currency or enter its code\n\nExample: <code>/exchange USD</code>\n\nAvailable: %s, GBP, JPY and more",
+	"exchange.loading":   "Fetching the exchange rate...",
+	"exchange.no_change": "unchanged",
+	"exchange.rise":      "up %.4f ₽",
+	"exchange.fall":      "down %.4f ₽",
+	"exchange.nominal":   " (per %d %s)",
+	"exchange.rate": `<b>%s exchange rate - %s</b>
+
+<b>Current rate:</b> %.4f ₽%s
+<b>Previous rate:</b> %.4f ₽
+<b>Change:</b> %s
+
+<i>Data from the Central Bank of Russia</i>`,
+	"exchange.not_found": "<b>Currency not found</b>\n\n<b>Available currencies:</b>\n",
+	"exchange.example":   "\n<i>Example: /exchange USD</i>",
+
+	"exchange.err.no_code":            "specify a currency code",
+	"exchange.err.connection":         "failed to connect to the exchange rate service",
+	"exchange.err.status":             "exchange rate service error (code %d)",
+	"exchange.err.decode":             "failed to process exchange rate data",
+	"exchange.err.not_published":      "no rates were published for this date",
+	"exchange.err.currency_not_found": "currency %s not found",
+	"exchange.err.date_format":        "enter the date as YYYY-MM-DD or a period such as <code>7d</code>",
+
+	// История курсов
+	"history.rate_on": `<b>%s exchange rate - %s on %s</b>
+
+<b>Rate:</b> %.4f ₽%s`,
+	"history.published_earlier": "\n\n<i>The Central Bank did not set a rate on this day, the rate from %s applied</i>",
+	"history.source":            "\n\n<i>Data from the Central Bank of Russia</i>",
+	"history.trend_rise":        "up %.4f ₽ (+%.2f%%)",
+	"history.trend_fall":        "down %.4f ₽ (%.2f%%)",
+	"history.summary": `<b>%s rate over %d %s (%s - %s)</b>
+
+<b>Current rate:</b> %.4f ₽
+<b>Low:</b> %.4f ₽ (%s)
+<b>High:</b> %.4f ₽ (%s)
+<b>Trend:</b> %s
+<b>Central Bank publications in the period:</b> %d
+
+<i>Rate per 1 %s, data from the Central Bank of Russia</i>`,
+
+	"history.err.future":      "the rate for a future date is unknown",
+	"history.err.not_in_data": "currency %s not found in the Central Bank data for %s",
+	"history.err.lookback":    "the Central Bank published no rates in the %d days before %s",
+	"history.err.period":      "the period must be between 1 and %d days",
+
+	// Конвертация
+	"convert.usage": "Specify the amount and currencies to convert\n\nExample: <code>/convert 150 USD EUR</code>",
+	"convert.result": `<b>Conversion %s → %s</b>
+
+<b>%.2f %s</b> = <b>%.2f %s</b>
+
+<b>Rate:</b> 1 %s = %.4f %s`,
+	"convert.via_rub":        "\n<b>Via ruble:</b> 1 %s = %.4f ₽, 1 %s = %.4f ₽",
+	"convert.footer":         "\n\n<i>At the Central Bank of Russia rate on %s</i>",
+	"convert.err.amount":     "the amount must be greater than zero",
+	"convert.err.not_number": "the amount must be a number",
+
+	// Новости
+	"news.loading":        "Loading the latest news...",
+	"news.title":          "<b>Top news of the day</b>\n\n",
+	"news.title_page":     "<b>Top news of the day (page %d)</b>\n\n",
+	"news.unknown_source": "Unknown source",
+	"news.source":         "<i>Source: %s</i>\n\n",
+	"news.footer":         "<i>Powered by NewsAPI</i>",
+	"news.stub": `<b>Top news of the day (demo mode)</b>
+
+<b>1. Software engineers' salaries keep growing</b>
+The average developer salary grew by 15% over the last year, according to a recruitment agency study.
+<i>Source: Reuters</i>
+
+<b>2. Remote work becomes the norm in IT</b>
+85% of IT companies are ready to let employees work fully remotely.
+<i>Source: Bloomberg</i>
+
+<b>3. Artificial intelligence is reshaping the job market</b>
+New professions are emerging around building and deploying AI solutions.
+<i>Source: The Economist</i>
+
+<b>4. Demand for Go developers is rising</b>
+Job openings for Go developers are up 40% compared to last year.
+<i>Source: LinkedIn</i>
+
+<b>5. New support measures for the tech industry</b>
+The government has announced additional benefits for IT companies and specialists.
+<i>Source: AP</i>
+
+<i>Set NEWS_API_KEY to get the latest news</i>`,
+
+	"news.err.page":       "the news page must be between 1 and %d",
+	"news.err.demo_page":  "only one news page is available in demo mode",
+	"news.err.no_more":    "no more news",
+	"news.err.connection": "failed to connect to the news service",
+	"news.err.bad_key":    "invalid NewsAPI key",
+	"news.err.rate_limit": "news API request limit exceeded",
+	"news.err.status":     "news service error (code %d)",
+	"news.err.decode":     "failed to process news data",
+	"news.err.response":   "failed to get news",
+
+	// Оповещения
+	"alert.usage":       "Specify the alert condition\n\nExamples:\n<code>/alert USD &gt; 95</code> - rate above 95 ₽\n<code>/alert EUR &lt; 90</code> - rate below 90 ₽\n<code>/alert CNY change 1%</code> - daily change over 1%\n<code>/alert delete 3</code> - delete an alert\n\nRates are in rubles per 1 unit of currency, as in /convert",
+	"alert.created":     "<b>Alert #%d created</b>\n\n%s\n<b>Current rate:</b> %.4f ₽\n\nAll alerts: /alerts",
+	"alert.none":        "You have no alerts\n\nCreate one: <code>/alert USD &gt; 95</code>",
+	"alert.list_title":  "<b>Your alerts</b>\n\n",
+	"alert.list_footer": "\nDelete: <code>/alert delete ID</code>",
+	"alert.not_found":   "Alert #%d not found",
+	"alert.deleted":     "Alert #%d deleted",
+	"alert.above":       "%s above %.4f ₽",
+	"alert.below":       "%s below %.4f ₽",
+	"alert.change":      "%s changes by more than %.2f%% in a day",
+	"alert.fired": `<b>Alert #%d triggered</b>
+
+%s
+<b>Current rate:</b> %.4f ₽
+<b>Change:</b> %s%.4f ₽
+
+<i>Data from the Central Bank of Russia</i>`,
+
+	"alert.err.limit": "you can create at most %d %s",
+	"alert.err.save":  "failed to save the alert, please try again later",
+
+	// Подписка на дайджест
+	"subscribe.usage":     "Specify the time of the daily digest\n\nExample: <code>/subscribe 08:00 London USD EUR</code>",
+	"subscribe.done":      "<b>Subscribed!</b>\n\n",
+	"subscribe.current":   "<b>Your subscription</b>\n\n",
+	"subscribe.none":      "You have no active subscription",
+	"subscribe.hint":      "\n\nSubscribe: <code>/subscribe 08:00 London USD EUR</code>",
+	"subscribe.cancelled": "Unsubscribed. You will no longer receive the daily digest",
+	"subscribe.details": `<b>Time:</b> %s (%s)
+<b>City:</b> %s
+<b>Currencies:</b> %s
+
+Cancel: /unsubscribe`,
+
+	"subscribe.err.time": "the time must be in HH:MM format, for example <code>08:30</code>",
+	"subscribe.err.save": "failed to save the subscription, please try again later",
+
+	"digest.title":         "<b>Your daily digest</b>",
+	"digest.weather_error": "<b>Weather:</b> %s",
+	"digest.rate_error":    "<b>%s rate:</b> %s",
+	"digest.news_error":    "<b>News:</b> %s",
+
+	// Inline-режим
+	"inline.weather_title":  "Weather: %s",
+	"inline.exchange_title": "%s rate",
+	"inline.news_title":     "Top news of the day",
+
+	// Названия валют: ЦБ публикует их только на русском
+	"currency.USD": "US Dollar",
+	"currency.EUR": "Euro",
+	"currency.CNY": "Chinese Yuan",
+	"currency.GBP": "British Pound",
+	"currency.JPY": "Japanese Yen",
+	"currency.CHF": "Swiss Franc",
+	"currency.TRY": "Turkish Lira",
+	"currency.KZT": "Kazakhstani Tenge",
+	"currency.BYN": "Belarusian Ruble",
+}
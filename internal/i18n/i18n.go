@@ -0,0 +1,159 @@
+// Package i18n - каталог пользовательских сообщений бота на русском и английском
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+type Lang string
+
+const (
+	RU Lang = "ru"
+	EN Lang = "en"
+
+	Default = RU
+)
+
+var catalogs = map[Lang]map[string]string{
+	RU: ru,
+	EN: en,
+}
+
+// Parse разбирает код языка: "ru", "EN", "en-US"
+func Parse(code string) (Lang, bool) {
+	base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(code)), "-")
+	lang := Lang(base)
+	if _, ok := catalogs[lang]; !ok {
+		return "", false
+	}
+	return lang, true
+}
+
+// FromTelegram выбирает язык по language_code пользователя Telegram.
+// Если код не передан, остаемся на русском, для остальных языков - английский
+func FromTelegram(code string) Lang {
+	if code == "" {
+		return Default
+	}
+	if lang, ok := Parse(code); ok {
+		return lang
+	}
+	// Пользователям из соседних стран русский обычно понятнее английского
+	switch base, _, _ := strings.Cut(strings.ToLower(code), "-"); base {
+	case "uk", "be", "kk":
+		return RU
+	}
+	return EN
+}
+
+// Lookup возвращает шаблон сообщения без подстановки аргументов
+func Lookup(lang Lang, key string) (string, bool) {
+	msg, ok := catalogs[lang][key]
+	return msg, ok
+}
+
+// T возвращает сообщение на языке lang. Если перевода нет, берется
+// русский вариант, если нет и его - сам ключ, чтобы пропуск был заметен
+func T(lang Lang, key string, args ...any) string {
+	msg, ok := Lookup(lang, key)
+	if !ok {
+		if msg, ok = Lookup(Default, key); !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+
+	// Аргументы не меняем на месте: их может повторно использовать Error
+	values := make([]any, len(args))
+	for i, arg := range args {
+		if d, ok := arg.(Duration); ok {
+			values[i] = d.text(lang)
+		} else {
+			values[i] = arg
+		}
+	}
+	return fmt.Sprintf(msg, values...)
+}
+
+// Duration - интервал, который подставляется в сообщение на его языке:
+// "45 с", "3 мин", "2 ч". Округляется вверх
+type Duration time.Duration
+
+func (d Duration) text(lang Lang) string {
+	seconds := int(math.Ceil(time.Duration(d).Seconds()))
+	switch {
+	case seconds < 60:
+		return T(lang, "duration.seconds", max(seconds, 1))
+	case seconds < 3600:
+		return T(lang, "duration.minutes", (seconds+59)/60)
+	default:
+		return T(lang, "duration.hours", (seconds+3599)/3600)
+	}
+}
+
+// Plural выбирает форму слова для числа n. Формы в каталоге перечислены
+// через "|": для русского - одна/несколько/много ("день|дня|дней"),
+// для английского - одна/много ("day|days")
+func Plural(lang Lang, key string, n int) string {
+	forms := strings.Split(T(lang, key), "|")
+
+	var form int
+	switch lang {
+	case RU:
+		form = russianForm(n)
+	default:
+		if n != 1 {
+			form = 1
+		}
+	}
+
+	if form >= len(forms) {
+		form = len(forms) - 1
+	}
+	return forms[form]
+}
+
+func russianForm(n int) int {
+	if n < 0 {
+		n = -n
+	}
+	switch mod10, mod100 := n%10, n%100; {
+	case mod10 == 1 && mod100 != 11:
+		return 0
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Error - ошибка, текст которой переводится при показе пользователю.
+// Error() отдает русский текст, поэтому в логах ошибки выглядят как раньше
+type Error struct {
+	Key  string
+	Args []any
+}
+
+func NewError(key string, args ...any) *Error {
+	return &Error{Key: key, Args: args}
+}
+
+func (e *Error) Error() string {
+	return T(Default, e.Key, e.Args...)
+}
+
+// ErrorText переводит ошибку для пользователя. Ошибки не из каталога
+// возвращаются как есть
+func ErrorText(lang Lang, err error) string {
+	var localized *Error
+	if errors.As(err, &localized) {
+		return T(lang, localized.Key, localized.Args...)
+	}
+	return err.Error()
+}
@@ -0,0 +1,357 @@
+package i18n
+
+// Шаблоны с аргументами проходят через fmt.Sprintf, поэтому знак процента
+// в них записывается как %%. В шаблонах без аргументов - как есть.
+var ru = map[string]string{
+	// Общее
+	"error":             "<b>Ошибка:</b> %s",
+	"unknown_command":   "Неизвестная команда. Список команд: /help",
+	"stale_note":        "\n\n<i>Сервис временно недоступен, показаны данные от %s</i>",
+	"callback.outdated": "Кнопка устарела, повторите команду",
+	"weekdays":          "Вс|Пн|Вт|Ср|Чт|Пт|Сб",
+
+	// Ограничение частоты запросов
+	"ratelimit.user":     "⏳ Слишком много запросов. Попробуйте через %s",
+	"ratelimit.provider": "лимит запросов к %s исчерпан, попробуйте через %s",
+	"duration.seconds":   "%d с",
+	"duration.minutes":   "%d мин",
+	"duration.hours":     "%d ч",
+
+	// Склонения: одна|несколько|много
+	"plural.day":   "день|дня|дней",
+	"plural.alert": "оповещение|оповещения|оповещений",
+
+	"start": `<b>Привет! Я ДейлиБот - твой помощник на каждый день!</b>
+
+<b>Мои команды:</b>
+/weather [город] - прогноз погоды
+/forecast [город] [дни] - прогноз на несколько дней
+/exchange [валюта] [дата|7d] - курс валют ЦБ РФ
+/convert сумма из в - конвертация валют
+/alert USD &gt; 95 - оповещение о курсе
+/news - главные новости дня
+/subscribe ЧЧ:ММ [город] [валюты] - ежедневный дайджест
+/lang ru|en - язык бота
+/settings - город, валюты и другие настройки
+/help - подробная справка`,
+
+	"help": `<b>Справка по командам:</b>
+
+<b>Мои команды:</b>
+<b>/weather [город]</b> - получить прогноз погоды
+Пример: <code>/weather Москва</code>
+
+<b>/forecast [город] [дни]</b> - прогноз на 1-5 дней
+Пример: <code>/forecast Казань 3</code>
+
+<b>/news</b> - топ-5 главных новостей дня
+Актуальные новости из российских источников
+
+<b>/exchange [валюта]</b> - курс валют по данным ЦБ РФ
+Пример: <code>/exchange USD</code> или <code>/exchange EUR</code>
+Курс на дату: <code>/exchange USD 2026-03-01</code>
+Динамика за период: <code>/exchange USD 7d</code>
+
+<b>/convert сумма из в</b> - конвертация по курсу ЦБ РФ
+Пример: <code>/convert 150 USD EUR</code> или <code>/convert 1000 RUB CNY</code>
+
+<b>/alert валюта условие</b> - оповещение о курсе
+Пример: <code>/alert USD &gt; 95</code>, <code>/alert EUR &lt; 90</code> или <code>/alert CNY change 1%</code>
+/alerts - список оповещений, <code>/alert delete ID</code> - удалить
+
+<b>/subscribe ЧЧ:ММ [город] [валюты]</b> - ежедневный дайджест
+Погода, курсы и новости одним сообщением в выбранное время
+Пример: <code>/subscribe 08:00 Москва USD EUR</code>
+/subscription - текущая подписка, /unsubscribe - отменить
+
+<b>/lang ru|en</b> - язык бота
+По умолчанию берется из настроек Telegram
+
+<b>/settings</b> - настройки профиля
+Город по умолчанию, избранные валюты, язык, единицы измерения, часовой пояс и время дайджеста
+
+<i>Бот работает на языке Go и использует официальные API</i>`,
+
+	// Язык
+	"lang.name":     "русский",
+	"lang.current":  "<b>Язык бота:</b> %s\n\nИзменить: <code>/lang ru</code> или <code>/lang en</code>",
+	"lang.unknown":  "Доступные языки: <code>ru</code>, <code>en</code>\n\nПример: <code>/lang en</code>",
+	"lang.changed":  "Готово, теперь я говорю по-русски",
+	"lang.err.save": "не удалось сохранить язык, попробуйте позже",
+
+	// Настройки
+	"settings.summary": `<b>Настройки</b>
+
+<b>Город:</b> %s
+<b>Избранные валюты:</b> %s
+<b>Язык:</b> %s
+<b>Единицы:</b> %s
+<b>Часовой пояс:</b> %s
+<b>Дайджест:</b> %s
+
+Город и валюты используются, когда /weather, /forecast и /exchange вызваны без аргументов`,
+	"settings.saved":             "✅ Сохранено\n\n",
+	"settings.not_set":           "не задан",
+	"settings.digest_off":        "выключен",
+	"settings.units.metric":      "метрические (°C, м/с)",
+	"settings.units.imperial":    "имперские (°F, миль/ч)",
+	"settings.city_prompt":       "Отправьте название города следующим сообщением. Чтобы искать погоду по координатам, пришлите геопозицию и нажмите «Сделать местом по умолчанию»",
+	"settings.currencies_prompt": "Отметьте избранные валюты",
+	"settings.lang_prompt":       "Выберите язык",
+	"settings.units_prompt":      "Выберите единицы измерения",
+	"settings.tz_prompt":         "Выберите часовой пояс или отправьте его название, например <code>Asia/Vladivostok</code>",
+	"settings.digest_prompt":     "Отправьте время ежедневного дайджеста в формате ЧЧ:ММ, например <code>08:00</code>",
+	"settings.err.city":          "название города должно быть не длиннее %d символов",
+	"settings.err.timezone":      "неизвестный часовой пояс %s",
+	"location.saved":             "Место по умолчанию: %s",
+	"settings.err.save":          "не удалось сохранить настройки, попробуйте позже",
+
+	// Кнопки
+	"button.refresh":         "Обновить",
+	"button.tomorrow":        "Прогноз на завтра",
+	"button.current_weather": "Текущая погода",
+	"button.back":            "Назад",
+	"button.more_news":       "Ещё новости",
+	"button.city":            "Город",
+	"button.currencies":      "Валюты",
+	"button.language":        "Язык",
+	"button.units":           "Единицы",
+	"button.timezone":        "Часовой пояс",
+	"button.digest":          "Дайджест",
+	"button.digest_off":      "Выключить дайджест",
+	"button.send_location":   "📍 Погода здесь",
+	"button.save_location":   "Сделать местом по умолчанию",
+
+	// Погода
+	"weather.usage":   "Укажите город для получения прогноза погоды\n\nПример: <code>/weather Москва</code>\nИли отправьте геопозицию. Город по умолчанию можно задать в /settings",
+	"weather.loading": "Получаю данные о погоде...",
+	"weather.clear":   "ясно",
+	"weather.current": `<b>Погода в городе %s</b>
+
+<b>Температура:</b> %d%s (ощущается как %d%s)
+<b>Описание:</b> %s
+<b>Влажность:</b> %d%%`,
+	"weather.wind":        "\n<b>Ветер:</b> %d %s",
+	"units.wind.metric":   "м/с",
+	"units.wind.imperial": "миль/ч",
+	"weather.pressure":    "\n<b>Давление:</b> %d мм рт.ст.",
+	"weather.stub": `<b>Погода в городе %s (демо-режим)</b>
+
+<b>Температура:</b> 22°C (ощущается как 24°C)
+<b>Описание:</b> переменная облачность
+<b>Влажность:</b> 65%%
+<b>Ветер:</b> 3 м/с
+<b>Давление:</b> 760 мм рт.ст.
+
+<i>Демо-режим: данные ненастоящие. Выключите DEMO_MODE, чтобы получать реальную погоду</i>`,
+	"weather.stub_description": "переменная облачность",
+	"weather.demo_hint":        "\n\n<i>Демо-режим: данные ненастоящие. Выключите DEMO_MODE, чтобы получать реальную погоду</i>",
+	"weather.source":           "\n\n<i>Источник: %s</i>",
+
+	"weather.err.connection":     "ошибка соединения с сервисом погоды",
+	"weather.err.decode":         "ошибка обработки данных о погоде",
+	"weather.err.response":       "ошибка получения данных о погоде",
+	"weather.err.city_not_found": "город '%s' не найден",
+	"weather.choose_place":       "Нашлось несколько мест с названием <b>%s</b>. Выберите нужное - я запомню выбор",
+	"weather.did_you_mean":       "\n\nВозможно, вы имели в виду:",
+	"weather.err.bad_key":        "неверный API ключ OpenWeather",
+	"weather.err.api":            "ошибка API: %s",
+	"weather.err.status":         "ошибка сервиса погоды (код %d)",
+
+	// Прогноз
+	"forecast.usage":               "Укажите город для получения прогноза\n\nПример: <code>/forecast Москва 3</code>\nГород по умолчанию можно задать в /settings",
+	"forecast.loading":             "Получаю прогноз погоды...",
+	"forecast.title":               "Прогноз погоды в городе %s",
+	"forecast.tomorrow_title":      "Прогноз на завтра в городе %s",
+	"forecast.stub_title":          "Прогноз погоды в городе %s (демо-режим)",
+	"forecast.tomorrow_stub_title": "Прогноз на завтра в городе %s (демо-режим)",
+	"forecast.day": `
+
+<b>%s, %s</b>
+<b>Температура:</b> от %d%s до %d%s
+<b>Описание:</b> %s
+<b>Вероятность осадков:</b> %d%%`,
+
+	"forecast.err.days":     "прогноз доступен на 1-%d дней",
+	"forecast.err.tomorrow": "прогноз на завтра недоступен",
+	"forecast.err.response": "ошибка получения прогноза погоды",
+
+	// Описания погоды по кодам WMO (Open-Meteo)
+	"wmo.0":  "ясно",
+	"wmo.1":  "преимущественно ясно",
+	"wmo.2":  "переменная облачность",
+	"wmo.3":  "пасмурно",
+	"wmo.45": "туман",
+	"wmo.48": "изморозь и туман",
+	"wmo.51": "слабая морось",
+	"wmo.53": "морось",
+	"wmo.55": "сильная морось",
+	"wmo.56": "слабая ледяная морось",
+	"wmo.57": "сильная ледяная морось",
+	"wmo.61": "небольшой дождь",
+	"wmo.63": "дождь",
+	"wmo.65": "сильный дождь",
+	"wmo.66": "слабый ледяной дождь",
+	"wmo.67": "сильный ледяной дождь",
+	"wmo.71": "небольшой снег",
+	"wmo.73": "снег",
+	"wmo.75": "сильный снег",
+	"wmo.77": "снежная крупа",
+	"wmo.80": "небольшой ливень",
+	"wmo.81": "ливень",
+	"wmo.82": "сильный ливень",
+	"wmo.85": "небольшой снегопад",
+	"wmo.86": "сильный снегопад",
+	"wmo.95": "гроза",
+	"wmo.96": "гроза с небольшим градом",
+	"wmo.99": "гроза с сильным градом",
+
+	// Курсы валют
+	"exchange.prompt":    "Выберите валюту или укажите код\n\nПример: <code>/exchange USD</code>\n\nДоступно: %s, GBP, JPY и другие",
+	"exchange.loading":   "Получаю актуальный курс валют...",
+	"exchange.no_change": "без изменений",
+	"exchange.rise":      "рост на %.4f ₽",
+	"exchange.fall":      "падение на %.4f ₽",
+	"exchange.nominal":   " (за %d %s)",
+	"exchange.rate": `<b>Курс валюты %s - %s</b>
+
+<b>Текущий курс:</b> %.4f ₽%s
+<b>Предыдущий курс:</b> %.4f ₽
+<b>Изменение:</b> %s
+
+<i>Данные Центрального банка РФ</i>`,
+	"exchange.not_found": "<b>Валюта не найдена</b>\n\n<b>Доступные валюты:</b>\n",
+	"exchange.example":   "\n<i>Пример: /exchange USD</i>",
+
+	"exchange.err.no_code":            "укажите код валюты",
+	"exchange.err.connection":         "ошибка соединения с сервисом курсов валют",
+	"exchange.err.status":             "ошибка сервиса курсов валют (код %d)",
+	"exchange.err.decode":             "ошибка обработки данных курсов валют",
+	"exchange.err.not_published":      "курсы на эту дату не публиковались",
+	"exchange.err.currency_not_found": "валюта %s не найдена",
+	"exchange.err.date_format":        "укажите дату в формате ГГГГ-ММ-ДД или период, например <code>7d</code>",
+
+	// История курсов
+	"history.rate_on": `<b>Курс валюты %s - %s на %s</b>
+
+<b>Курс:</b> %.4f ₽%s`,
+	"history.published_earlier": "\n\n<i>В этот день ЦБ курс не устанавливал, действовал курс от %s</i>",
+	"history.source":            "\n\n<i>Данные Центрального банка РФ</i>",
+	"history.trend_rise":        "рост на %.4f ₽ (+%.2f%%)",
+	"history.trend_fall":        "падение на %.4f ₽ (%.2f%%)",
+	"history.summary": `<b>Курс %s за %d %s (%s - %s)</b>
+
+<b>Текущий курс:</b> %.4f ₽
+<b>Минимум:</b> %.4f ₽ (%s)
+<b>Максимум:</b> %.4f ₽ (%s)
+<b>Тренд:</b> %s
+<b>Публикаций ЦБ за период:</b> %d
+
+<i>Курс за 1 %s, данные Центрального банка РФ</i>`,
+
+	"history.err.future":      "курс на будущую дату неизвестен",
+	"history.err.not_in_data": "валюта %s не найдена в данных ЦБ на %s",
+	"history.err.lookback":    "ЦБ не публиковал курсы в течение %d дней до %s",
+	"history.err.period":      "период должен быть от 1 до %d дней",
+
+	// Конвертация
+	"convert.usage": "Укажите сумму и валюты для конвертации\n\nПример: <code>/convert 150 USD EUR</code>",
+	"convert.result": `<b>Конвертация %s → %s</b>
+
+<b>%.2f %s</b> = <b>%.2f %s</b>
+
+<b>Курс:</b> 1 %s = %.4f %s`,
+	"convert.via_rub":        "\n<b>Через рубль:</b> 1 %s = %.4f ₽, 1 %s = %.4f ₽",
+	"convert.footer":         "\n\n<i>По курсу ЦБ РФ на %s</i>",
+	"convert.err.amount":     "сумма должна быть больше нуля",
+	"convert.err.not_number": "сумма должна быть числом",
+
+	// Новости
+	"news.loading":        "Загружаю актуальные новости...",
+	"news.title":          "<b>Главные новости дня</b>\n\n",
+	"news.title_page":     "<b>Главные новости дня (стр. %d)</b>\n\n",
+	"news.unknown_source": "Неизвестный источник",
+	"news.source":         "<i>Источник: %s</i>\n\n",
+	"news.footer":         "<i>Данные предоставлены NewsAPI</i>",
+	"news.stub": `<b>Главные новости дня (демо-режим)</b>
+
+<b>1. Российские IT-специалисты показывают рост зарплат</b>
+Средняя зарплата разработчиков выросла на 15% за последний год согласно исследованию рекрутингового агентства.
+<i>Источник: РБК</i>
+
+<b>2. Удаленная работа становится стандартом для IT-сферы</b>
+85% российских IT-компаний готовы предоставить сотрудникам возможность полностью удаленной работы.
+<i>Источник: Ведомости</i>
+
+<b>3. Искусственный интеллект меняет рынок труда</b>
+Появляются новые профессии связанные с разработкой и внедрением ИИ-решений в российских компаниях.
+<i>Источник: Коммерсант</i>
+
+<b>4. Рост спроса на Go-разработчиков</b>
+Язык программирования Go показывает увеличение вакансий на 40% по сравнению с прошлым годом.
+<i>Источник: HeadHunter</i>
+
+<b>5. Новые меры поддержки IT-отрасли</b>
+Правительство анонсировало дополнительные льготы для IT-компаний и специалистов.
+<i>Источник: ТАСС</i>
+
+<i>Для получения актуальных новостей настройте NEWS_API_KEY</i>`,
+
+	"news.err.page":       "страница новостей должна быть от 1 до %d",
+	"news.err.demo_page":  "в демо-режиме доступна только одна страница новостей",
+	"news.err.no_more":    "больше новостей нет",
+	"news.err.connection": "ошибка соединения с сервисом новостей",
+	"news.err.bad_key":    "неверный API ключ NewsAPI",
+	"news.err.rate_limit": "превышен лимит запросов к API новостей",
+	"news.err.status":     "ошибка сервиса новостей (код %d)",
+	"news.err.decode":     "ошибка обработки данных новостей",
+	"news.err.response":   "ошибка получения новостей",
+
+	// Оповещения
+	"alert.usage":       "Укажите условие оповещения\n\nПримеры:\n<code>/alert USD &gt; 95</code> - курс выше 95 ₽\n<code>/alert EUR &lt; 90</code> - курс ниже 90 ₽\n<code>/alert CNY change 1%</code> - изменение за день больше 1%\n<code>/alert delete 3</code> - удалить оповещение\n\nКурс считается в рублях за 1 единицу валюты, как в /convert",
+	"alert.created":     "<b>Оповещение #%d создано</b>\n\n%s\n<b>Текущий курс:</b> %.4f ₽\n\nВсе оповещения: /alerts",
+	"alert.none":        "У вас нет оповещений\n\nСоздать: <code>/alert USD &gt; 95</code>",
+	"alert.list_title":  "<b>Ваши оповещения</b>\n\n",
+	"alert.list_footer": "\nУдалить: <code>/alert delete ID</code>",
+	"alert.not_found":   "Оповещение #%d не найдено",
+	"alert.deleted":     "Оповещение #%d удалено",
+	"alert.above":       "%s выше %.4f ₽",
+	"alert.below":       "%s ниже %.4f ₽",
+	"alert.change":      "%s изменится за день больше чем на %.2f%%",
+	"alert.fired": `<b>Оповещение #%d сработало</b>
+
+%s
+<b>Текущий курс:</b> %.4f ₽
+<b>Изменение:</b> %s%.4f ₽
+
+<i>Данные Центрального банка РФ</i>`,
+
+	"alert.err.limit": "можно создать не больше %d %s",
+	"alert.err.save":  "не удалось сохранить оповещение, попробуйте позже",
+
+	// Подписка на дайджест
+	"subscribe.usage":     "Укажите время ежедневной рассылки\n\nПример: <code>/subscribe 08:00 Москва USD EUR</code>",
+	"subscribe.done":      "<b>Подписка оформлена!</b>\n\n",
+	"subscribe.current":   "<b>Ваша подписка</b>\n\n",
+	"subscribe.none":      "У вас нет активной подписки",
+	"subscribe.hint":      "\n\nОформить: <code>/subscribe 08:00 Москва USD EUR</code>",
+	"subscribe.cancelled": "Подписка отменена. Ежедневный дайджест больше не будет приходить",
+	"subscribe.details": `<b>Время:</b> %s (%s)
+<b>Город:</b> %s
+<b>Валюты:</b> %s
+
+Отменить: /unsubscribe`,
+
+	"subscribe.err.time": "время должно быть в формате ЧЧ:ММ, например <code>08:30</code>",
+	"subscribe.err.save": "не удалось сохранить подписку, попробуйте позже",
+
+	"digest.title":         "<b>Ваш ежедневный дайджест</b>",
+	"digest.weather_error": "<b>Погода:</b> %s",
+	"digest.rate_error":    "<b>Курс %s:</b> %s",
+	"digest.news_error":    "<b>Новости:</b> %s",
+
+	// Inline-режим
+	"inline.weather_title":  "Погода: %s",
+	"inline.exchange_title": "Курс %s",
+	"inline.news_title":     "Главные новости дня",
+}
@@ -0,0 +1,124 @@
+// Package metrics - метрики бота в формате Prometheus: команды, запросы
+// к внешним API, ошибки Telegram, пользователи и рантайм Go
+package metrics
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "dailybot"
+
+var registry = prometheus.NewRegistry()
+
+var (
+	commandsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "commands_total",
+		Help:      "Commands handled, by command.",
+	}, []string{"command"})
+
+	commandDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "command_duration_seconds",
+		Help:      "Time spent handling a command, including upstream calls and Telegram replies.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"command"})
+
+	upstreamTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_requests_total",
+		Help:      "Requests to external APIs by provider and HTTP status (\"error\" - no response).",
+	}, []string{"provider", "status"})
+
+	upstreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upstream_request_duration_seconds",
+		Help:      "Latency of external API requests by provider and HTTP status.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10},
+	}, []string{"provider", "status"})
+
+	telegramErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "telegram_errors_total",
+		Help:      "Failed Telegram Bot API calls by method and HTTP status (\"error\" - no response).",
+	}, []string{"method", "status"})
+
+	users = &usersCollector{
+		active: prometheus.NewDesc(namespace+"_active_users", "Chats active in the last 24 hours.", nil, nil),
+		total:  prometheus.NewDesc(namespace+"_users", "Chats that have ever sent a command.", nil, nil),
+	}
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		commandsTotal, commandDuration,
+		upstreamTotal, upstreamDuration,
+		telegramErrors,
+		users,
+	)
+}
+
+// ObserveCommand учитывает обработанную команду и время ее обработки
+func ObserveCommand(command string, started time.Time) {
+	commandsTotal.WithLabelValues(command).Inc()
+	commandDuration.WithLabelValues(command).Observe(time.Since(started).Seconds())
+}
+
+// observeUpstream учитывает запрос к внешнему API. status 0 - ответа не было
+func observeUpstream(provider string, status int, duration time.Duration) {
+	label := statusLabel(status)
+	upstreamTotal.WithLabelValues(provider, label).Inc()
+	upstreamDuration.WithLabelValues(provider, label).Observe(duration.Seconds())
+}
+
+func statusLabel(status int) string {
+	if status == 0 {
+		return "error"
+	}
+	return strconv.Itoa(status)
+}
+
+// UsersFunc возвращает число активных за сутки и всех известных чатов
+type UsersFunc func() (active, total int)
+
+// SetUsersSource подключает источник числа пользователей (статистику админки)
+func SetUsersSource(f UsersFunc) {
+	users.mu.Lock()
+	defer users.mu.Unlock()
+	users.source = f
+}
+
+// usersCollector считает пользователей в момент сбора метрик, чтобы
+// не дублировать статистику админки
+type usersCollector struct {
+	mu     sync.Mutex
+	source UsersFunc
+	active *prometheus.Desc
+	total  *prometheus.Desc
+}
+
+func (c *usersCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.active
+	ch <- c.total
+}
+
+func (c *usersCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	source := c.source
+	c.mu.Unlock()
+
+	if source == nil {
+		return
+	}
+
+	active, total := source()
+	ch <- prometheus.MustNewConstMetric(c.active, prometheus.GaugeValue, float64(active))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(total))
+}
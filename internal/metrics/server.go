@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Server отдает /metrics на отдельном адресе, чтобы метрики можно было
+// открыть только для Prometheus, не открывая админку
+type Server struct {
+	server *http.Server
+}
+
+// NewServer - сервер метрик на адресе listen. Непустой token требуется
+// в заголовке "Authorization: Bearer ..." (bearer_token в Prometheus)
+func NewServer(listen, token string) *Server {
+	handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", requireToken(token, handler))
+
+	return &Server{server: &http.Server{
+		Addr:              listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}}
+}
+
+func requireToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		value, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(value), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) Start() {
+	log.Printf("📈 Metrics: http://%s/metrics", s.server.Addr)
+
+	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("❌ Failed to start metrics server: %v", err)
+	}
+}
+
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
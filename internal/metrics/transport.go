@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Провайдеры по хосту запроса. Хосты Open-Meteo различаются поддоменом
+var providerHosts = map[string]string{
+	"api.openweathermap.org":       "OpenWeather",
+	"newsapi.org":                  "NewsAPI",
+	"www.cbr-xml-daily.ru":         "CBR",
+	"api.open-meteo.com":           "Open-Meteo",
+	"geocoding-api.open-meteo.com": "Open-Meteo",
+}
+
+// UpstreamTransport считает запросы к внешним API и их время по провайдеру
+// и коду ответа. Запросы к неизвестным хостам учитываются как "other"
+type UpstreamTransport struct {
+	Next http.RoundTripper // nil - http.DefaultTransport
+}
+
+func (t UpstreamTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	provider, ok := providerHosts[req.URL.Hostname()]
+	if !ok {
+		provider = "other"
+	}
+
+	started := time.Now()
+	resp, err := next.RoundTrip(req)
+
+	status := 0
+	if err == nil {
+		status = resp.StatusCode
+	}
+	observeUpstream(provider, status, time.Since(started))
+
+	return resp, err
+}
+
+// HTTPClient - клиент, через который tgbotapi ходит в Telegram
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// TelegramClient считает неудачные вызовы Bot API. Telegram отвечает на
+// ошибки HTTP-кодом (400, 403, 429), поэтому тело ответа читать не нужно
+type TelegramClient struct {
+	Next HTTPClient
+}
+
+func (c TelegramClient) Do(req *http.Request) (*http.Response, error) {
+	resp, err := c.Next.Do(req)
+
+	switch {
+	case err != nil:
+		telegramErrors.WithLabelValues(telegramMethod(req), statusLabel(0)).Inc()
+	case resp.StatusCode >= http.StatusBadRequest:
+		telegramErrors.WithLabelValues(telegramMethod(req), statusLabel(resp.StatusCode)).Inc()
+	}
+
+	return resp, err
+}
+
+// telegramMethod - метод Bot API из пути /bot<token>/<method>.
+// Путь целиком в метки не попадает: в нем токен бота
+func telegramMethod(req *http.Request) string {
+	path := req.URL.Path
+	return path[strings.LastIndex(path, "/")+1:]
+}
@@ -0,0 +1,309 @@
+// Package ratelimit - ограничение частоты запросов: token bucket на пару
+// пользователь+команда, общий бюджет запросов к каждому внешнему провайдеру
+// и список заблокированных чатов
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultCommand - лимит для команд, у которых нет своего
+const DefaultCommand = "*"
+
+// Когда корзин становится больше, полные (давно не использованные) удаляются
+const maxBuckets = 10000
+
+// Limit - не больше Requests запросов за Window. Запас восстанавливается
+// равномерно, поэтому после паузы можно сразу сделать Requests запросов
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Window)
+}
+
+func (l Limit) valid() bool {
+	return l.Requests > 0 && l.Window > 0
+}
+
+// Лимиты по умолчанию. Новости дороже всего: бесплатный тариф NewsAPI - 100 запросов в сутки,
+// у Open-Meteo для некоммерческого использования - 10000
+var (
+	DefaultCommandLimits = map[string]Limit{
+		DefaultCommand: {Requests: 20, Window: time.Minute},
+		"weather":      {Requests: 10, Window: time.Minute},
+		"forecast":     {Requests: 10, Window: time.Minute},
+		"news":         {Requests: 5, Window: time.Minute},
+		"inline":       {Requests: 30, Window: time.Minute},
+	}
+	DefaultProviderLimits = map[string]Limit{
+		"OpenWeather": {Requests: 50, Window: time.Minute},
+		"Open-Meteo":  {Requests: 10000, Window: 24 * time.Hour},
+		"NewsAPI":     {Requests: 100, Window: 24 * time.Hour},
+		"ЦБ РФ":       {Requests: 60, Window: time.Minute},
+	}
+)
+
+// Decision - результат проверки запроса пользователя
+type Decision struct {
+	Allowed    bool
+	RetryAfter time.Duration // через сколько появится свободный запрос
+	Notify     bool          // первый отказ подряд - стоит ответить пользователю
+}
+
+// LimitInfo - лимит и текущий запас для админки
+type LimitInfo struct {
+	Name      string
+	Limit     Limit
+	Available int // для провайдеров - сколько запросов осталось сейчас
+}
+
+// Ban - заблокированный чат или пользователь
+type Ban struct {
+	ID     int64
+	Reason string
+	Since  time.Time
+}
+
+type bucket struct {
+	limit  Limit
+	tokens float64
+	last   time.Time
+	warned bool
+}
+
+func newBucket(limit Limit, now time.Time) *bucket {
+	return &bucket{limit: limit, tokens: float64(limit.Requests), last: now}
+}
+
+func (b *bucket) refill(now time.Time) {
+	rate := float64(b.limit.Requests) / b.limit.Window.Seconds()
+	b.tokens = min(float64(b.limit.Requests), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+}
+
+// take забирает запрос из корзины. Если запаса нет - возвращает, сколько ждать
+func (b *bucket) take(now time.Time) (time.Duration, bool) {
+	b.refill(now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+
+	rate := float64(b.limit.Requests) / b.limit.Window.Seconds()
+	seconds := math.Ceil((1 - b.tokens) / rate)
+	return time.Duration(seconds) * time.Second, false
+}
+
+type userKey struct {
+	userID  int64
+	command string
+}
+
+type Limiter struct {
+	mu sync.Mutex
+
+	commandLimits map[string]Limit
+	users         map[userKey]*bucket
+	providers     map[string]*bucket
+	bans          map[int64]Ban
+}
+
+func New(commandLimits, providerLimits map[string]Limit) *Limiter {
+	l := &Limiter{
+		commandLimits: make(map[string]Limit),
+		users:         make(map[userKey]*bucket),
+		providers:     make(map[string]*bucket),
+		bans:          make(map[int64]Ban),
+	}
+
+	for command, limit := range commandLimits {
+		l.commandLimits[command] = limit
+	}
+	for provider, limit := range providerLimits {
+		l.providers[provider] = newBucket(limit, time.Now())
+	}
+
+	return l
+}
+
+// Allow проверяет запрос пользователя к команде. Команды без своего
+// лимита расходуют одну общую корзину пользователя
+func (l *Limiter) Allow(userID int64, command string) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit, ok := l.commandLimits[command]
+	if !ok {
+		command = DefaultCommand
+		limit, ok = l.commandLimits[DefaultCommand]
+	}
+	if !ok {
+		return Decision{Allowed: true}
+	}
+
+	now := time.Now()
+	key := userKey{userID, command}
+	b, exists := l.users[key]
+	if !exists {
+		if len(l.users) >= maxBuckets {
+			l.pruneLocked(now)
+		}
+		b = newBucket(limit, now)
+		l.users[key] = b
+	}
+
+	wait, allowed := b.take(now)
+	if allowed {
+		b.warned = false
+		return Decision{Allowed: true}
+	}
+
+	notify := !b.warned
+	b.warned = true
+	return Decision{RetryAfter: wait, Notify: notify}
+}
+
+// pruneLocked удаляет корзины, которые успели наполниться: для них
+// новая корзина ничем не отличается от старой
+func (l *Limiter) pruneLocked(now time.Time) {
+	for key, b := range l.users {
+		if b.refill(now); b.tokens >= float64(b.limit.Requests) {
+			delete(l.users, key)
+		}
+	}
+}
+
+// AllowProvider расходует общий бюджет запросов к провайдеру.
+// Для провайдеров без лимита всегда разрешает
+func (l *Limiter) AllowProvider(provider string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.providers[provider]
+	if !ok {
+		return 0, true
+	}
+	return b.take(time.Now())
+}
+
+// SetCommandLimit меняет лимит команды. Запас пользователей по этой команде
+// начинается заново
+func (l *Limiter) SetCommandLimit(command string, limit Limit) error {
+	if !limit.valid() {
+		return fmt.Errorf("invalid limit %s", limit)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.commandLimits[command] = limit
+	for key := range l.users {
+		if key.command == command {
+			delete(l.users, key)
+		}
+	}
+	return nil
+}
+
+// SetProviderLimit меняет бюджет провайдера, сохраняя уже израсходованную часть
+func (l *Limiter) SetProviderLimit(provider string, limit Limit) error {
+	if !limit.valid() {
+		return fmt.Errorf("invalid limit %s", limit)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	b, ok := l.providers[provider]
+	if !ok {
+		l.providers[provider] = newBucket(limit, now)
+		return nil
+	}
+
+	b.refill(now)
+	b.tokens = min(b.tokens, float64(limit.Requests))
+	b.limit = limit
+	return nil
+}
+
+func (l *Limiter) CommandLimits() []LimitInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limits := make([]LimitInfo, 0, len(l.commandLimits))
+	for command, limit := range l.commandLimits {
+		limits = append(limits, LimitInfo{Name: command, Limit: limit})
+	}
+	sort.Slice(limits, func(i, j int) bool { return limits[i].Name < limits[j].Name })
+	return limits
+}
+
+func (l *Limiter) ProviderLimits() []LimitInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	limits := make([]LimitInfo, 0, len(l.providers))
+	for provider, b := range l.providers {
+		b.refill(now)
+		limits = append(limits, LimitInfo{Name: provider, Limit: b.limit, Available: int(b.tokens)})
+	}
+	sort.Slice(limits, func(i, j int) bool { return limits[i].Name < limits[j].Name })
+	return limits
+}
+
+// Ban блокирует чат или пользователя: их обновления игнорируются
+func (l *Limiter) Ban(id int64, reason string) Ban {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ban := Ban{ID: id, Reason: reason, Since: time.Now()}
+	l.bans[id] = ban
+	return ban
+}
+
+// Restore восстанавливает блокировку, сохраненную ранее
+func (l *Limiter) Restore(ban Ban) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.bans[ban.ID] = ban
+}
+
+// Unban снимает блокировку. false - блокировки не было
+func (l *Limiter) Unban(id int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, exists := l.bans[id]
+	delete(l.bans, id)
+	return exists
+}
+
+func (l *Limiter) IsBanned(id int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, banned := l.bans[id]
+	return banned
+}
+
+func (l *Limiter) Bans() []Ban {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bans := make([]Ban, 0, len(l.bans))
+	for _, ban := range l.bans {
+		bans = append(bans, ban)
+	}
+	sort.Slice(bans, func(i, j int) bool { return bans[i].Since.Before(bans[j].Since) })
+	return bans
+}
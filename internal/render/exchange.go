@@ -0,0 +1,139 @@
+package render
+
+import (
+	"dailybot/internal/api"
+	"dailybot/internal/i18n"
+	"fmt"
+	"time"
+)
+
+// Rate - текущий курс валюты и его изменение
+func (r Renderer) Rate(q api.RateQuote) string {
+	currency := q.Currency
+
+	change := currency.Value - currency.Previous
+	changeText := r.t("exchange.no_change")
+
+	if change > 0 {
+		changeText = r.t("exchange.rise", change)
+	} else if change < 0 {
+		changeText = r.t("exchange.fall", -change)
+	}
+
+	return r.Text(r.t("exchange.rate",
+		currency.CharCode,
+		r.currencyName(currency),
+		currency.Value,
+		r.nominalText(currency),
+		currency.Previous,
+		changeText) + r.staleNote(q.Stale, q.FetchedAt))
+}
+
+// UnknownCurrency - ответ на неизвестный код валюты: список популярных
+func (r Renderer) UnknownCurrency(err *api.UnknownCurrencyError) string {
+	result := r.t("exchange.not_found")
+
+	for _, currency := range err.Available {
+		result += fmt.Sprintf("• %s - %s\n", currency.CharCode, r.currencyName(currency))
+	}
+
+	result += r.t("exchange.example")
+	return r.Text(result)
+}
+
+// RateOn - курс на дату. Если в этот день ЦБ курс не устанавливал,
+// поясняет, от какого числа курс
+func (r Renderer) RateOn(h api.HistoricalRate) string {
+	result := r.t("history.rate_on",
+		h.Currency.CharCode,
+		r.currencyName(h.Currency),
+		h.Requested.Format("02.01.2006"),
+		h.Currency.Value,
+		r.nominalText(h.Currency))
+
+	if publishedDate := formatCBRDate(h.Published); publishedDate != h.Requested.Format("02.01.2006") {
+		result += r.t("history.published_earlier", publishedDate)
+	}
+
+	result += r.t("history.source")
+	return r.Text(result)
+}
+
+// RateHistory - минимум, максимум и тренд курса за период
+func (r Renderer) RateHistory(h api.RateHistory) string {
+	points := h.Points
+	first, last := points[0], points[len(points)-1]
+	low, high := first, first
+	for _, p := range points {
+		if p.Value < low.Value {
+			low = p
+		}
+		if p.Value > high.Value {
+			high = p
+		}
+	}
+
+	change := last.Value - first.Value
+	trendText := r.t("exchange.no_change")
+	if change > 0 {
+		trendText = r.t("history.trend_rise", change, change/first.Value*100)
+	} else if change < 0 {
+		trendText = r.t("history.trend_fall", -change, change/first.Value*100)
+	}
+
+	return r.Text(r.t("history.summary",
+		h.Code, h.Days, i18n.Plural(r.Lang, "plural.day", h.Days),
+		first.Date.Format("02.01.2006"), last.Date.Format("02.01.2006"),
+		last.Value,
+		low.Value, low.Date.Format("02.01.2006"),
+		high.Value, high.Date.Format("02.01.2006"),
+		trendText,
+		len(points),
+		h.Code))
+}
+
+// Conversion - пересчет суммы по курсу ЦБ
+func (r Renderer) Conversion(c api.Conversion) string {
+	result := r.t("convert.result",
+		c.From, c.To,
+		c.Amount, c.From,
+		c.Amount*c.Rate, c.To,
+		c.From, c.Rate, c.To)
+
+	// Для пар без рубля показываем, через какие курсы считали
+	if c.From != "RUB" && c.To != "RUB" {
+		result += r.t("convert.via_rub", c.From, c.FromRate, c.To, c.ToRate)
+	}
+
+	result += r.t("convert.footer", formatCBRDate(c.Date))
+	return r.Text(result + r.staleNote(c.Stale, c.FetchedAt))
+}
+
+// nominalText поясняет, за сколько единиц указан курс: " (за 100 JPY)"
+func (r Renderer) nominalText(currency api.Currency) string {
+	if currency.Nominal <= 1 {
+		return ""
+	}
+	return r.t("exchange.nominal", currency.Nominal, currency.CharCode)
+}
+
+// currencyName возвращает название валюты. ЦБ публикует названия только
+// на русском, для других языков берем перевод из каталога или код валюты
+func (r Renderer) currencyName(currency api.Currency) string {
+	if r.Lang == i18n.RU {
+		return currency.Name
+	}
+	if name, ok := i18n.Lookup(r.Lang, "currency."+currency.CharCode); ok {
+		return name
+	}
+	return currency.CharCode
+}
+
+// formatCBRDate переводит дату ЦБ (RFC3339) в привычный формат ДД.ММ.ГГГГ
+func formatCBRDate(date string) string {
+	t, err := time.Parse(time.RFC3339, date)
+	if err != nil {
+		return date
+	}
+	return t.Format("02.01.2006")
+}
@@ -0,0 +1,52 @@
+package render
+
+import (
+	"dailybot/internal/api"
+	"fmt"
+	"html"
+)
+
+// News - страница новостей со сквозной нумерацией
+func (r Renderer) News(n api.NewsPage) string {
+	if n.Demo {
+		return r.Text(r.t("news.stub"))
+	}
+
+	result := r.t("news.title")
+	if n.Page > 1 {
+		result = r.t("news.title_page", n.Page)
+	}
+	offset := (n.Page - 1) * api.NewsPageSize
+
+	for i, article := range n.Articles {
+		title := html.EscapeString(truncate(article.Title, 100))
+
+		source := r.t("news.unknown_source")
+		if article.Source != "" {
+			source = html.EscapeString(article.Source)
+		}
+
+		result += fmt.Sprintf("<b>%d. %s</b>\n", offset+i+1, title)
+
+		if article.Description != "" {
+			description := html.EscapeString(truncate(article.Description, 150))
+			result += fmt.Sprintf("%s\n", description)
+		}
+
+		result += r.t("news.source", source)
+	}
+
+	result += r.t("news.footer")
+	return r.Text(result + r.staleNote(n.Stale, n.FetchedAt))
+}
+
+// truncate обрезает текст до limit символов с многоточием. Считаются руны,
+// а не байты: иначе кириллица режется посреди буквы. Обрезать нужно до
+// экранирования, чтобы не разрезать HTML-сущность
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-3]) + "..."
+}
@@ -0,0 +1,179 @@
+// Package render - представление данных из api для пользователя. Шаблоны
+// каталога i18n написаны в HTML-разметке Telegram, поэтому ответ сначала
+// собирается в HTML, а для других форматов переводится из него
+package render
+
+import (
+	"dailybot/internal/i18n"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Format - формат текста сообщения
+type Format string
+
+const (
+	HTML       Format = "HTML"
+	Plain      Format = "plain"
+	MarkdownV2 Format = "MarkdownV2"
+)
+
+// Renderer превращает результаты api в текст на языке Lang в формате Format
+type Renderer struct {
+	Format Format
+	Lang   i18n.Lang
+}
+
+func New(format Format, lang i18n.Lang) Renderer {
+	return Renderer{Format: format, Lang: lang}
+}
+
+// ParseMode - значение parse_mode для Telegram. Для простого текста - пустое
+func (r Renderer) ParseMode() string {
+	if r.Format == Plain {
+		return ""
+	}
+	return string(r.Format)
+}
+
+// Text переводит сообщение из каталога (HTML) в формат рендерера
+func (r Renderer) Text(htmlText string) string {
+	return Convert(htmlText, r.Format)
+}
+
+func (r Renderer) t(key string, args ...any) string {
+	return i18n.T(r.Lang, key, args...)
+}
+
+// staleNote - пометка для ответа, собранного из устаревших данных
+func (r Renderer) staleNote(stale bool, fetchedAt time.Time) string {
+	if !stale {
+		return ""
+	}
+	return r.t("stale_note", fetchedAt.Format("02.01 15:04"))
+}
+
+// Теги, которые понимает Telegram. Остальные "<" считаются текстом:
+// в заголовках новостей и названиях мест они встречаются как есть
+var tagRe = regexp.MustCompile(`^<(/?)(b|strong|i|em|u|ins|s|strike|del|code|pre|a)(\s+href="([^"]*)")?\s*>`)
+
+// Convert переводит HTML-разметку Telegram в другой формат
+func Convert(htmlText string, format Format) string {
+	switch format {
+	case Plain:
+		return convert(htmlText, plainWriter{})
+	case MarkdownV2:
+		return convert(htmlText, &markdownWriter{})
+	}
+	return htmlText
+}
+
+// tagWriter собирает текст в целевом формате по мере разбора HTML
+type tagWriter interface {
+	text(sb *strings.Builder, s string)
+	open(sb *strings.Builder, tag, href string)
+	close(sb *strings.Builder, tag string)
+}
+
+func convert(htmlText string, w tagWriter) string {
+	var sb strings.Builder
+
+	rest := htmlText
+	for rest != "" {
+		i := strings.IndexByte(rest, '<')
+		if i < 0 {
+			w.text(&sb, html.UnescapeString(rest))
+			break
+		}
+		if i > 0 {
+			w.text(&sb, html.UnescapeString(rest[:i]))
+			rest = rest[i:]
+		}
+
+		match := tagRe.FindStringSubmatch(rest)
+		if match == nil {
+			w.text(&sb, "<")
+			rest = rest[1:]
+			continue
+		}
+
+		if match[1] == "/" {
+			w.close(&sb, match[2])
+		} else {
+			w.open(&sb, match[2], html.UnescapeString(match[4]))
+		}
+		rest = rest[len(match[0]):]
+	}
+
+	return sb.String()
+}
+
+// plainWriter оставляет только текст
+type plainWriter struct{}
+
+func (plainWriter) text(sb *strings.Builder, s string)         { sb.WriteString(s) }
+func (plainWriter) open(sb *strings.Builder, tag, href string) {}
+func (plainWriter) close(sb *strings.Builder, tag string)      {}
+
+// markdownWriter переводит теги в разметку MarkdownV2 и экранирует
+// служебные символы. Внутри кода экранируются только ` и \
+type markdownWriter struct {
+	code  int
+	hrefs []string
+}
+
+var (
+	markdownEscaper = strings.NewReplacer(
+		`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+		"~", `\~`, "`", "\\`", ">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`,
+		"|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+	)
+	markdownCodeEscaper = strings.NewReplacer(`\`, `\\`, "`", "\\`")
+	markdownURLEscaper  = strings.NewReplacer(`\`, `\\`, ")", `\)`)
+)
+
+var markdownMarks = map[string]string{
+	"b": "*", "strong": "*",
+	"i": "_", "em": "_",
+	"u": "__", "ins": "__",
+	"s": "~", "strike": "~", "del": "~",
+	"code": "`", "pre": "```",
+}
+
+func (m *markdownWriter) text(sb *strings.Builder, s string) {
+	if m.code > 0 {
+		sb.WriteString(markdownCodeEscaper.Replace(s))
+		return
+	}
+	sb.WriteString(markdownEscaper.Replace(s))
+}
+
+func (m *markdownWriter) open(sb *strings.Builder, tag, href string) {
+	switch tag {
+	case "a":
+		m.hrefs = append(m.hrefs, href)
+		sb.WriteString("[")
+		return
+	case "code", "pre":
+		m.code++
+	}
+	sb.WriteString(markdownMarks[tag])
+}
+
+func (m *markdownWriter) close(sb *strings.Builder, tag string) {
+	switch tag {
+	case "a":
+		if len(m.hrefs) == 0 {
+			return
+		}
+		href := m.hrefs[len(m.hrefs)-1]
+		m.hrefs = m.hrefs[:len(m.hrefs)-1]
+		sb.WriteString("](" + markdownURLEscaper.Replace(href) + ")")
+		return
+	case "code", "pre":
+		m.code = max(m.code-1, 0)
+	}
+	sb.WriteString(markdownMarks[tag])
+}
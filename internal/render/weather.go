@@ -0,0 +1,122 @@
+package render
+
+import (
+	"dailybot/internal/api"
+	"fmt"
+	"strings"
+	"time"
+)
+
+func temperatureUnit(units api.Units) string {
+	if units == api.Imperial {
+		return "°F"
+	}
+	return "°C"
+}
+
+func (r Renderer) windUnit(units api.Units) string {
+	return r.t("units.wind." + string(api.ParseUnits(string(units))))
+}
+
+// placeHeading - место и страна для заголовка: "Москва, RU"
+func placeHeading(place, country string) string {
+	if country == "" {
+		return place
+	}
+	return place + ", " + country
+}
+
+// weekdayName - короткое название дня недели, в каталоге они перечислены
+// через "|" начиная с воскресенья
+func (r Renderer) weekdayName(day time.Weekday) string {
+	return strings.Split(r.t("weekdays"), "|")[day]
+}
+
+// Weather - текущая погода с подписью источника
+func (r Renderer) Weather(w api.Weather) string {
+	return r.Text(r.weatherHTML(w))
+}
+
+func (r Renderer) weatherHTML(w api.Weather) string {
+	if w.Demo {
+		return r.t("weather.stub", w.Place)
+	}
+
+	description := w.Description
+	if description == "" {
+		description = r.t("weather.clear")
+	}
+
+	result := r.t("weather.current",
+		placeHeading(w.Place, w.Country),
+		int(w.Temp), temperatureUnit(w.Units),
+		int(w.FeelsLike), temperatureUnit(w.Units),
+		description,
+		w.Humidity)
+
+	// Добавляем дополнительные данные если они есть
+	if w.WindSpeed > 0 {
+		windSpeed := int(w.WindSpeed)
+		result += r.t("weather.wind", windSpeed, r.windUnit(w.Units))
+	}
+
+	if w.Pressure > 0 {
+		pressureMmHg := int(float64(w.Pressure) * 0.75006)
+		result += r.t("weather.pressure", pressureMmHg)
+	}
+
+	return result + r.t("weather.source", w.Source) + r.staleNote(w.Stale, w.FetchedAt)
+}
+
+// Forecast - прогноз по дням. Для прогноза на завтра - свой заголовок
+func (r Renderer) Forecast(f api.Forecast) string {
+	return r.Text(r.forecastHTML(f))
+}
+
+func (r Renderer) forecastHTML(f api.Forecast) string {
+	if f.Demo {
+		titleKey := "forecast.stub_title"
+		if f.Tomorrow {
+			titleKey = "forecast.tomorrow_stub_title"
+		}
+		return r.forecastDays(r.t(titleKey, f.Place), f.Days, f.Units, r.t("weather.stub_description")) + r.t("weather.demo_hint")
+	}
+
+	titleKey := "forecast.title"
+	if f.Tomorrow {
+		titleKey = "forecast.tomorrow_title"
+	}
+	title := r.t(titleKey, placeHeading(f.Place, f.Country))
+
+	return r.forecastDays(title, f.Days, f.Units, "") +
+		r.t("weather.source", f.Source) +
+		r.staleNote(f.Stale, f.FetchedAt)
+}
+
+// forecastDays - заголовок и карточки дней. Непустой description заменяет
+// описания дней (в демо-режиме)
+func (r Renderer) forecastDays(title string, days []api.DayForecast, units api.Units, description string) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "<b>%s</b>", title)
+
+	for _, day := range days {
+		dayDescription := description
+		if dayDescription == "" {
+			dayDescription = day.Description
+		}
+		if dayDescription == "" {
+			dayDescription = r.t("weather.clear")
+		}
+
+		sb.WriteString(r.t("forecast.day",
+			r.weekdayName(day.Date.Weekday()),
+			day.Date.Format("02.01"),
+			int(day.TempMin), temperatureUnit(units),
+			int(day.TempMax), temperatureUnit(units),
+			dayDescription,
+			int(day.PrecipMax*100+0.5)))
+	}
+
+	return sb.String()
+}
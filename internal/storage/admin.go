@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// AdminAccount - учетная запись админки, созданная в панели
+type AdminAccount struct {
+	Login        string
+	PasswordHash string
+	Role         string
+	CreatedAt    time.Time
+}
+
+// AuditEntry - запись журнала действий в админке
+type AuditEntry struct {
+	Actor     string
+	Action    string
+	Details   string
+	CreatedAt time.Time
+}
+
+// APIToken - токен для API админки. Сам токен не хранится, только его хеш
+type APIToken struct {
+	Hash      string
+	Name      string
+	CreatedBy string
+	CreatedAt time.Time
+}
+
+func (s *Storage) SaveAdminAccount(ctx context.Context, account AdminAccount) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO admin_accounts (login, password_hash, role, created_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (login) DO UPDATE SET password_hash = EXCLUDED.password_hash, role = EXCLUDED.role`,
+		account.Login, account.PasswordHash, account.Role, account.CreatedAt)
+	return err
+}
+
+func (s *Storage) DeleteAdminAccount(ctx context.Context, login string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM admin_accounts WHERE login = $1`, login)
+	return err
+}
+
+func (s *Storage) ListAdminAccounts(ctx context.Context) ([]AdminAccount, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT login, password_hash, role, created_at FROM admin_accounts ORDER BY login`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []AdminAccount
+	for rows.Next() {
+		var a AdminAccount
+		if err := rows.Scan(&a.Login, &a.PasswordHash, &a.Role, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, a)
+	}
+
+	return accounts, rows.Err()
+}
+
+func (s *Storage) AddAuditEntry(ctx context.Context, entry AuditEntry) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO admin_audit (actor, action, details, created_at) VALUES ($1, $2, $3, $4)`,
+		entry.Actor, entry.Action, entry.Details, entry.CreatedAt)
+	return err
+}
+
+// ListAuditEntries возвращает последние limit записей журнала, старые - первыми
+func (s *Storage) ListAuditEntries(ctx context.Context, limit int) ([]AuditEntry, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT actor, action, details, created_at FROM (
+			SELECT id, actor, action, details, created_at FROM admin_audit ORDER BY id DESC LIMIT $1
+		) recent ORDER BY id`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []AuditEntry
+	for rows.Next() {
+		var e AuditEntry
+		if err := rows.Scan(&e.Actor, &e.Action, &e.Details, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+func (s *Storage) SaveAPIToken(ctx context.Context, token APIToken) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO api_tokens (token_hash, name, created_by, created_at) VALUES ($1, $2, $3, $4)`,
+		token.Hash, token.Name, token.CreatedBy, token.CreatedAt)
+	return err
+}
+
+func (s *Storage) DeleteAPIToken(ctx context.Context, hash string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM api_tokens WHERE token_hash = $1`, hash)
+	return err
+}
+
+func (s *Storage) ListAPITokens(ctx context.Context) ([]APIToken, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT token_hash, name, created_by, created_at FROM api_tokens ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []APIToken
+	for rows.Next() {
+		var t APIToken
+		if err := rows.Scan(&t.Hash, &t.Name, &t.CreatedBy, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+
+	return tokens, rows.Err()
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+)
+
+// Виды оповещений о курсе
+const (
+	AlertAbove  = "above"  // курс поднялся выше порога
+	AlertBelow  = "below"  // курс опустился ниже порога
+	AlertChange = "change" // дневное изменение больше порога в процентах
+)
+
+// Alert - оповещение пользователя об изменении курса валюты
+type Alert struct {
+	ID        int64
+	ChatID    int64
+	Currency  string
+	Kind      string
+	Threshold float64
+	Triggered bool   // условие выполнено на последнем снимке, ждем обратного пересечения
+	LastDate  string // дата снимка ЦБ, по которому последний раз проверяли изменение
+}
+
+func (s *Storage) CreateAlert(ctx context.Context, alert Alert) (int64, error) {
+	var id int64
+	err := s.db.QueryRowContext(ctx, `INSERT INTO alerts (chat_id, currency, kind, threshold, triggered, last_date)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		alert.ChatID, alert.Currency, alert.Kind, alert.Threshold, alert.Triggered, alert.LastDate).Scan(&id)
+	return id, err
+}
+
+func (s *Storage) DeleteAlert(ctx context.Context, chatID, id int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM alerts WHERE id = $1 AND chat_id = $2`, id, chatID)
+	return err
+}
+
+func (s *Storage) UpdateAlertState(ctx context.Context, alert Alert) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE alerts SET triggered = $2, last_date = $3 WHERE id = $1`,
+		alert.ID, alert.Triggered, alert.LastDate)
+	return err
+}
+
+func (s *Storage) ListAlerts(ctx context.Context) ([]Alert, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, chat_id, currency, kind, threshold, triggered, last_date
+		FROM alerts ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []Alert
+	for rows.Next() {
+		var a Alert
+		if err := rows.Scan(&a.ID, &a.ChatID, &a.Currency, &a.Kind, &a.Threshold, &a.Triggered, &a.LastDate); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, a)
+	}
+
+	return alerts, rows.Err()
+}
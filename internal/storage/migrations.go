@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// migrations применяются по порядку, номер версии - индекс + 1.
+// Уже примененные миграции менять нельзя - только добавлять новые в конец.
+var migrations = []string{
+	// 1: счетчики команд и активность пользователей
+	`CREATE TABLE command_stats (
+		command TEXT PRIMARY KEY,
+		count   BIGINT NOT NULL DEFAULT 0
+	);
+	CREATE TABLE user_activity (
+		user_id   BIGINT PRIMARY KEY,
+		last_seen TIMESTAMPTZ NOT NULL
+	);`,
+
+	// 2: подписки на ежедневный дайджест
+	`CREATE TABLE subscriptions (
+		chat_id    BIGINT PRIMARY KEY,
+		send_time  TEXT NOT NULL,
+		city       TEXT NOT NULL,
+		currencies TEXT NOT NULL,
+		timezone   TEXT NOT NULL,
+		last_sent  TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);`,
+
+	// 3: оповещения о курсах валют
+	`CREATE TABLE alerts (
+		id         BIGSERIAL PRIMARY KEY,
+		chat_id    BIGINT NOT NULL,
+		currency   TEXT NOT NULL,
+		kind       TEXT NOT NULL,
+		threshold  DOUBLE PRECISION NOT NULL,
+		triggered  BOOLEAN NOT NULL DEFAULT false,
+		last_date  TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);
+	CREATE INDEX alerts_chat_id_idx ON alerts (chat_id);`,
+
+	// 4: язык интерфейса, выбранный командой /lang
+	`CREATE TABLE user_settings (
+		chat_id    BIGINT PRIMARY KEY,
+		lang       TEXT NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);`,
+
+	// 5: настройки становятся профилем пользователя
+	`ALTER TABLE user_settings RENAME TO user_profiles;
+	ALTER TABLE user_profiles
+		ALTER COLUMN lang SET DEFAULT '',
+		ADD COLUMN city       TEXT NOT NULL DEFAULT '',
+		ADD COLUMN currencies TEXT NOT NULL DEFAULT '',
+		ADD COLUMN units      TEXT NOT NULL DEFAULT 'metric',
+		ADD COLUMN timezone   TEXT NOT NULL DEFAULT '';`,
+
+	// 6: место по умолчанию по координатам из геопозиции
+	`ALTER TABLE user_profiles
+		ADD COLUMN latitude  DOUBLE PRECISION,
+		ADD COLUMN longitude DOUBLE PRECISION;`,
+
+	// 7: какое из одноименных мест выбрал пользователь
+	`CREATE TABLE place_choices (
+		chat_id    BIGINT           NOT NULL,
+		query      TEXT             NOT NULL,
+		name       TEXT             NOT NULL,
+		state      TEXT             NOT NULL DEFAULT '',
+		country    TEXT             NOT NULL DEFAULT '',
+		latitude   DOUBLE PRECISION NOT NULL,
+		longitude  DOUBLE PRECISION NOT NULL,
+		updated_at TIMESTAMPTZ      NOT NULL DEFAULT now(),
+		PRIMARY KEY (chat_id, query)
+	);`,
+
+	// 8: блокировки и лимиты, измененные в админке
+	`CREATE TABLE bans (
+		chat_id    BIGINT PRIMARY KEY,
+		reason     TEXT        NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);
+	CREATE TABLE rate_limits (
+		kind           TEXT    NOT NULL,
+		name           TEXT    NOT NULL,
+		requests       INTEGER NOT NULL,
+		window_seconds INTEGER NOT NULL,
+		PRIMARY KEY (kind, name)
+	);`,
+
+	// 9: учетные записи админки и журнал действий
+	`CREATE TABLE admin_accounts (
+		login         TEXT PRIMARY KEY,
+		password_hash TEXT        NOT NULL,
+		role          TEXT        NOT NULL,
+		created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+	);
+	CREATE TABLE admin_audit (
+		id         BIGSERIAL PRIMARY KEY,
+		actor      TEXT        NOT NULL,
+		action     TEXT        NOT NULL,
+		details    TEXT        NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);
+	CREATE INDEX admin_audit_created_at_idx ON admin_audit (created_at);`,
+
+	// 10: токены для API админки, хранится только SHA-256 токена
+	`CREATE TABLE api_tokens (
+		token_hash TEXT PRIMARY KEY,
+		name       TEXT        NOT NULL,
+		created_by TEXT        NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	);`,
+
+	// 11: координаты места дайджеста, если это сохраненная геопозиция.
+	// Подписки, созданные с такого места раньше, получают координаты из профиля
+	`ALTER TABLE subscriptions
+		ADD COLUMN latitude  DOUBLE PRECISION,
+		ADD COLUMN longitude DOUBLE PRECISION;
+	UPDATE subscriptions s SET latitude = p.latitude, longitude = p.longitude
+		FROM user_profiles p
+		WHERE p.chat_id = s.chat_id AND p.city = s.city AND p.latitude IS NOT NULL;`,
+
+	// 12: общие счетчики бота. Число сообщений раньше считалось суммой
+	// счетчиков команд - с нее и начинаем
+	`CREATE TABLE bot_counters (
+		name  TEXT PRIMARY KEY,
+		value BIGINT NOT NULL DEFAULT 0
+	);
+	INSERT INTO bot_counters (name, value)
+		SELECT 'messages', COALESCE(SUM(count), 0) FROM command_stats;`,
+}
+
+func (s *Storage) migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return err
+	}
+
+	var current int
+	if err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return err
+	}
+
+	for i := current; i < len(migrations); i++ {
+		version := i + 1
+
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, version); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %d: %w", version, err)
+		}
+
+		log.Printf("Applied migration %d", version)
+	}
+
+	return nil
+}
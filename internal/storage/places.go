@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+)
+
+// PlaceChoice - место, выбранное пользователем для неоднозначного названия города
+type PlaceChoice struct {
+	ChatID  int64
+	Query   string // название в том виде, как его ищет бот (нижний регистр, одинарные пробелы)
+	Name    string
+	State   string
+	Country string
+	Lat     float64
+	Lon     float64
+}
+
+func (s *Storage) SavePlaceChoice(ctx context.Context, c PlaceChoice) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO place_choices (chat_id, query, name, state, country, latitude, longitude)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (chat_id, query) DO UPDATE SET
+			name = EXCLUDED.name,
+			state = EXCLUDED.state,
+			country = EXCLUDED.country,
+			latitude = EXCLUDED.latitude,
+			longitude = EXCLUDED.longitude,
+			updated_at = now()`,
+		c.ChatID, c.Query, c.Name, c.State, c.Country, c.Lat, c.Lon)
+	return err
+}
+
+func (s *Storage) ListPlaceChoices(ctx context.Context) ([]PlaceChoice, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT chat_id, query, name, state, country, latitude, longitude
+		FROM place_choices ORDER BY chat_id, query`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var choices []PlaceChoice
+	for rows.Next() {
+		var c PlaceChoice
+		if err := rows.Scan(&c.ChatID, &c.Query, &c.Name, &c.State, &c.Country, &c.Lat, &c.Lon); err != nil {
+			return nil, err
+		}
+		choices = append(choices, c)
+	}
+
+	return choices, rows.Err()
+}
@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// Profile - настройки пользователя. Пустые поля - значения по умолчанию
+type Profile struct {
+	ChatID     int64
+	Lang       string // пусто - язык берется из настроек Telegram
+	City       string
+	Currencies []string
+	Units      string // metric или imperial
+	Timezone   string // имя зоны IANA, пусто - часовой пояс из конфига
+	// Location - координаты из присланной геопозиции. Если заданы,
+	// текущая погода ищется по ним, а City хранит название для остального
+	Location *Location
+}
+
+type Location struct {
+	Lat float64
+	Lon float64
+}
+
+func (s *Storage) SaveProfile(ctx context.Context, p Profile) error {
+	var lat, lon sql.NullFloat64
+	if p.Location != nil {
+		lat = sql.NullFloat64{Float64: p.Location.Lat, Valid: true}
+		lon = sql.NullFloat64{Float64: p.Location.Lon, Valid: true}
+	}
+
+	_, err := s.db.ExecContext(ctx, `INSERT INTO user_profiles (chat_id, lang, city, currencies, units, timezone, latitude, longitude)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (chat_id) DO UPDATE SET
+			lang = EXCLUDED.lang,
+			city = EXCLUDED.city,
+			currencies = EXCLUDED.currencies,
+			units = EXCLUDED.units,
+			timezone = EXCLUDED.timezone,
+			latitude = EXCLUDED.latitude,
+			longitude = EXCLUDED.longitude,
+			updated_at = now()`,
+		p.ChatID, p.Lang, p.City, strings.Join(p.Currencies, ","), p.Units, p.Timezone, lat, lon)
+	return err
+}
+
+func (s *Storage) ListProfiles(ctx context.Context) ([]Profile, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT chat_id, lang, city, currencies, units, timezone, latitude, longitude
+		FROM user_profiles ORDER BY chat_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []Profile
+	for rows.Next() {
+		var p Profile
+		var currencies string
+		var lat, lon sql.NullFloat64
+		if err := rows.Scan(&p.ChatID, &p.Lang, &p.City, &currencies, &p.Units, &p.Timezone, &lat, &lon); err != nil {
+			return nil, err
+		}
+		if currencies != "" {
+			p.Currencies = strings.Split(currencies, ",")
+		}
+		if lat.Valid && lon.Valid {
+			p.Location = &Location{Lat: lat.Float64, Lon: lon.Float64}
+		}
+		profiles = append(profiles, p)
+	}
+
+	return profiles, rows.Err()
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// Виды лимитов
+const (
+	LimitCommand  = "command"  // на пользователя и команду
+	LimitProvider = "provider" // общий на внешний API
+)
+
+// Ban - заблокированный чат или пользователь
+type Ban struct {
+	ChatID    int64
+	Reason    string
+	CreatedAt time.Time
+}
+
+// RateLimit - лимит, измененный в админке
+type RateLimit struct {
+	Kind     string
+	Name     string
+	Requests int
+	Window   time.Duration
+}
+
+func (s *Storage) SaveBan(ctx context.Context, ban Ban) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO bans (chat_id, reason, created_at) VALUES ($1, $2, $3)
+		ON CONFLICT (chat_id) DO UPDATE SET reason = EXCLUDED.reason, created_at = EXCLUDED.created_at`,
+		ban.ChatID, ban.Reason, ban.CreatedAt)
+	return err
+}
+
+func (s *Storage) DeleteBan(ctx context.Context, chatID int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM bans WHERE chat_id = $1`, chatID)
+	return err
+}
+
+func (s *Storage) ListBans(ctx context.Context) ([]Ban, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT chat_id, reason, created_at FROM bans ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bans []Ban
+	for rows.Next() {
+		var b Ban
+		if err := rows.Scan(&b.ChatID, &b.Reason, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		bans = append(bans, b)
+	}
+
+	return bans, rows.Err()
+}
+
+func (s *Storage) SaveRateLimit(ctx context.Context, limit RateLimit) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO rate_limits (kind, name, requests, window_seconds) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (kind, name) DO UPDATE SET requests = EXCLUDED.requests, window_seconds = EXCLUDED.window_seconds`,
+		limit.Kind, limit.Name, limit.Requests, int(limit.Window.Seconds()))
+	return err
+}
+
+func (s *Storage) ListRateLimits(ctx context.Context) ([]RateLimit, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT kind, name, requests, window_seconds FROM rate_limits ORDER BY kind, name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var limits []RateLimit
+	for rows.Next() {
+		var l RateLimit
+		var windowSeconds int
+		if err := rows.Scan(&l.Kind, &l.Name, &l.Requests, &windowSeconds); err != nil {
+			return nil, err
+		}
+		l.Window = time.Duration(windowSeconds) * time.Second
+		limits = append(limits, l)
+	}
+
+	return limits, rows.Err()
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// CommandStats - накопленная статистика за все время работы бота
+type CommandStats struct {
+	Messages    int64
+	Commands    map[string]int64
+	ActiveUsers map[int64]time.Time
+}
+
+// SaveStats прибавляет накопленные счетчики сообщений и команд и обновляет
+// время активности пользователей
+func (s *Storage) SaveStats(ctx context.Context, messages int64, commands map[string]int64, users map[int64]time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if messages > 0 {
+		_, err = tx.ExecContext(ctx, `INSERT INTO bot_counters (name, value) VALUES ('messages', $1)
+			ON CONFLICT (name) DO UPDATE SET value = bot_counters.value + EXCLUDED.value`, messages)
+		if err != nil {
+			return err
+		}
+	}
+
+	for command, count := range commands {
+		_, err = tx.ExecContext(ctx, `INSERT INTO command_stats (command, count) VALUES ($1, $2)
+			ON CONFLICT (command) DO UPDATE SET count = command_stats.count + EXCLUDED.count`, command, count)
+		if err != nil {
+			return err
+		}
+	}
+
+	for userID, lastSeen := range users {
+		_, err = tx.ExecContext(ctx, `INSERT INTO user_activity (user_id, last_seen) VALUES ($1, $2)
+			ON CONFLICT (user_id) DO UPDATE SET last_seen = GREATEST(user_activity.last_seen, EXCLUDED.last_seen)`, userID, lastSeen)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// LoadStats загружает счетчики сообщений и команд и активность пользователей
+func (s *Storage) LoadStats(ctx context.Context) (*CommandStats, error) {
+	stats := &CommandStats{
+		Commands:    make(map[string]int64),
+		ActiveUsers: make(map[int64]time.Time),
+	}
+
+	err := s.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(value), 0) FROM bot_counters WHERE name = 'messages'`).Scan(&stats.Messages)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT command, count FROM command_stats`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var command string
+		var count int64
+		if err := rows.Scan(&command, &count); err != nil {
+			return nil, err
+		}
+		stats.Commands[command] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	userRows, err := s.db.QueryContext(ctx, `SELECT user_id, last_seen FROM user_activity`)
+	if err != nil {
+		return nil, err
+	}
+	defer userRows.Close()
+
+	for userRows.Next() {
+		var userID int64
+		var lastSeen time.Time
+		if err := userRows.Scan(&userID, &lastSeen); err != nil {
+			return nil, err
+		}
+		stats.ActiveUsers[userID] = lastSeen
+	}
+
+	return stats, userRows.Err()
+}
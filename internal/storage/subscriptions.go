@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+// Subscription - подписка чата на ежедневный дайджест
+type Subscription struct {
+	ChatID     int64
+	Time       string // HH:MM по местному времени подписчика
+	City       string
+	Currencies []string
+	Timezone   string // имя зоны IANA, например Europe/Moscow
+	LastSent   string // дата последней отправки в формате 2006-01-02
+	// Location - координаты сохраненной геопозиции. Если заданы, погода
+	// ищется по ним, а City - только название для показа
+	Location *Location
+}
+
+func (s *Storage) SaveSubscription(ctx context.Context, sub Subscription) error {
+	var lat, lon sql.NullFloat64
+	if sub.Location != nil {
+		lat = sql.NullFloat64{Float64: sub.Location.Lat, Valid: true}
+		lon = sql.NullFloat64{Float64: sub.Location.Lon, Valid: true}
+	}
+
+	_, err := s.db.ExecContext(ctx, `INSERT INTO subscriptions (chat_id, send_time, city, currencies, timezone, last_sent, latitude, longitude)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (chat_id) DO UPDATE SET
+			send_time = EXCLUDED.send_time,
+			city = EXCLUDED.city,
+			currencies = EXCLUDED.currencies,
+			timezone = EXCLUDED.timezone,
+			last_sent = EXCLUDED.last_sent,
+			latitude = EXCLUDED.latitude,
+			longitude = EXCLUDED.longitude`,
+		sub.ChatID, sub.Time, sub.City, strings.Join(sub.Currencies, ","), sub.Timezone, sub.LastSent, lat, lon)
+	return err
+}
+
+func (s *Storage) DeleteSubscription(ctx context.Context, chatID int64) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM subscriptions WHERE chat_id = $1`, chatID)
+	return err
+}
+
+func (s *Storage) MarkDigestSent(ctx context.Context, chatID int64, date string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE subscriptions SET last_sent = $2 WHERE chat_id = $1`, chatID, date)
+	return err
+}
+
+func (s *Storage) ListSubscriptions(ctx context.Context) ([]Subscription, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT chat_id, send_time, city, currencies, timezone, last_sent, latitude, longitude
+		FROM subscriptions ORDER BY chat_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []Subscription
+	for rows.Next() {
+		var sub Subscription
+		var currencies string
+		var lat, lon sql.NullFloat64
+		if err := rows.Scan(&sub.ChatID, &sub.Time, &sub.City, &currencies, &sub.Timezone, &sub.LastSent, &lat, &lon); err != nil {
+			return nil, err
+		}
+		if currencies != "" {
+			sub.Currencies = strings.Split(currencies, ",")
+		}
+		if lat.Valid && lon.Valid {
+			sub.Location = &Location{Lat: lat.Float64, Lon: lon.Float64}
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}